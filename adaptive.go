@@ -0,0 +1,191 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// LimitAlgorithm decides how the adaptive concurrency limit reacts to measurements
+type LimitAlgorithm int
+
+const (
+	AIMD     LimitAlgorithm = iota // Add one on success, multiply by BackoffRatio on overload
+	Gradient                       // Follow the ratio between the long-term and current latency
+)
+
+// AdaptiveLimitConfig defines the configuration for adaptive concurrency limiting
+type AdaptiveLimitConfig struct {
+	Algorithm     LimitAlgorithm
+	InitialLimit  int           // Starting limit (default 20)
+	MinLimit      int           // Lowest limit (default 1)
+	MaxLimit      int           // Highest limit (default 200)
+	BackoffRatio  float64       // Limit multiplier on errors, 429 and 503 responses (default 0.9)
+	LatencyTarget time.Duration // AIMD treats slower responses as overload (default 0, only errors count)
+	Tolerance     float64       // Gradient tolerates latency up to this multiple of the long-term average (default 1.5)
+	MaxQueue      int           // Maximum requests waiting for a slot (0 means unlimited, negative disables queueing)
+}
+
+// WithAdaptiveLimit caps the requests in flight with a limit adjusted from observed latency and errors.
+// Requests over the limit wait by priority like WithBulkhead. Metrics implementing LimitMetrics
+// receive the current limit.
+func WithAdaptiveLimit(config AdaptiveLimitConfig) Option {
+	return func(c *Client) {
+		c.adaptive = newAdaptiveLimiter(config)
+	}
+}
+
+// ConcurrencyLimit returns the current adaptive concurrency limit, 0 without WithAdaptiveLimit
+func (c *Client) ConcurrencyLimit() int {
+	if c.adaptive == nil {
+		return 0
+	}
+	return c.adaptive.current()
+}
+
+type adaptiveLimiter struct {
+	*limiter
+	config  AdaptiveLimitConfig
+	metrics LimitMetrics
+
+	mu      sync.Mutex
+	limit   float64
+	longRTT float64 // exponentially smoothed latency in nanoseconds
+}
+
+func newAdaptiveLimiter(config AdaptiveLimitConfig) *adaptiveLimiter {
+	if config.MinLimit <= 0 {
+		config.MinLimit = 1
+	}
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = 200
+	}
+	if config.InitialLimit <= 0 {
+		config.InitialLimit = 20
+	}
+	config.InitialLimit = min(max(config.InitialLimit, config.MinLimit), config.MaxLimit)
+	if config.BackoffRatio <= 0 || config.BackoffRatio >= 1 {
+		config.BackoffRatio = 0.9
+	}
+	if config.Tolerance < 1 {
+		config.Tolerance = 1.5
+	}
+
+	return &adaptiveLimiter{
+		limiter: newLimiter(config.InitialLimit, config.MaxQueue),
+		config:  config,
+		limit:   float64(config.InitialLimit),
+	}
+}
+
+func (a *adaptiveLimiter) current() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return int(a.limit)
+}
+
+// adaptiveMiddleware holds a slot per attempt until the response body is closed and
+// feeds the time until response headers into the limit
+func (c *Client) adaptiveMiddleware() Middleware {
+	a := c.adaptive
+	a.metrics, _ = c.metrics.(LimitMetrics)
+	if a.metrics != nil {
+		a.metrics.ConcurrencyLimit(a.current())
+	}
+
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			priority := PriorityNormal
+			if state := callStateFrom(ctx); state != nil {
+				priority = state.priority
+			}
+			if err := a.acquire(ctx, priority); err != nil {
+				return nil, err
+			}
+
+			inFlight := a.inFlightCount()
+			start := time.Now()
+			resp, err := next(req)
+			a.observe(time.Since(start), inFlight, overloaded(ctx, resp, err))
+
+			if err != nil {
+				a.release()
+				return nil, err
+			}
+			resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: a.release}
+			return resp, nil
+		}
+	}
+}
+
+// overloaded reports whether an attempt signals that the upstream is saturated: network
+// errors such as failed dials, reads and timeouts, exceeded deadlines, 429 and 503.
+// Errors raised by the client itself, like hook errors or injected faults, don't count.
+func overloaded(ctx context.Context, resp *http.Response, err error) bool {
+	if err == nil {
+		return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable
+	}
+	// Callers giving up say nothing about the upstream, timeouts do
+	if errors.Is(ctx.Err(), context.Canceled) || isHookError(err) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	// Every error from http.Client is a *url.Error, look at what it wraps
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// observe updates the limit from one measurement
+func (a *adaptiveLimiter) observe(rtt time.Duration, inFlight int, overload bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	old := int(a.limit)
+	limit := a.limit
+
+	switch {
+	case overload:
+		limit *= a.config.BackoffRatio
+	case a.config.Algorithm == Gradient:
+		sample := float64(rtt)
+		if a.longRTT == 0 {
+			a.longRTT = sample
+		} else {
+			a.longRTT = a.longRTT*0.95 + sample*0.05
+		}
+		// Without pressure the latency says nothing about the limit
+		if float64(inFlight)*2 >= limit {
+			gradient := math.Max(0.5, math.Min(1, a.config.Tolerance*a.longRTT/math.Max(sample, 1)))
+			next := limit*gradient + math.Sqrt(limit)
+			limit = limit*0.8 + next*0.2
+		}
+	default:
+		if a.config.LatencyTarget > 0 && rtt > a.config.LatencyTarget {
+			limit *= a.config.BackoffRatio
+		} else if float64(inFlight)*2 >= limit {
+			limit++
+		}
+	}
+
+	a.limit = math.Max(float64(a.config.MinLimit), math.Min(float64(a.config.MaxLimit), limit))
+	current := int(a.limit)
+
+	// Applied under the lock so concurrent updates can't reorder
+	if current != old {
+		a.setLimit(current)
+		if a.metrics != nil {
+			a.metrics.ConcurrencyLimit(current)
+		}
+	}
+}
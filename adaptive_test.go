@@ -0,0 +1,160 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// limitRecordingMetrics also records the adaptive limit
+type limitRecordingMetrics struct {
+	recordingMetrics
+}
+
+func (m *limitRecordingMetrics) ConcurrencyLimit(limit int) {
+	m.record("limit %d", limit)
+}
+
+func TestWithAdaptiveLimit_BacksOffOnOverload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	metrics := &limitRecordingMetrics{}
+	client := NewClient(server.URL,
+		WithMetrics(metrics),
+		WithAdaptiveLimit(AdaptiveLimitConfig{InitialLimit: 10, BackoffRatio: 0.5}),
+	)
+
+	if client.ConcurrencyLimit() != 10 {
+		t.Errorf("Expected initial limit 10, got %d", client.ConcurrencyLimit())
+	}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(context.Background(), "/")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if client.ConcurrencyLimit() != 2 {
+		t.Errorf("Expected the limit to halve twice to 2, got %d", client.ConcurrencyLimit())
+	}
+
+	var limits []string
+	for _, event := range metrics.snapshot() {
+		if strings.HasPrefix(event, "limit ") {
+			limits = append(limits, event)
+		}
+	}
+	assertEvents(t, limits, []string{"limit 10", "limit 5", "limit 2"})
+}
+
+func TestWithAdaptiveLimit_Unconfigured(t *testing.T) {
+	if limit := NewClient("http://example.com").ConcurrencyLimit(); limit != 0 {
+		t.Errorf("Expected 0 without an adaptive limit, got %d", limit)
+	}
+}
+
+func TestAdaptiveLimiter_AIMD(t *testing.T) {
+	a := newAdaptiveLimiter(AdaptiveLimitConfig{InitialLimit: 4, MaxLimit: 6, LatencyTarget: 100 * time.Millisecond})
+
+	// Little load leaves the limit alone
+	a.observe(time.Millisecond, 1, false)
+	if a.current() != 4 {
+		t.Errorf("Expected an idle limit to stay at 4, got %d", a.current())
+	}
+
+	for i := 0; i < 5; i++ {
+		a.observe(time.Millisecond, a.current(), false)
+	}
+	if a.current() != 6 {
+		t.Errorf("Expected the limit to grow up to the maximum of 6, got %d", a.current())
+	}
+
+	a.observe(time.Second, 6, false)
+	if a.current() != 5 {
+		t.Errorf("Expected a slow response to reduce the limit to 5, got %d", a.current())
+	}
+}
+
+func TestAdaptiveLimiter_Gradient(t *testing.T) {
+	a := newAdaptiveLimiter(AdaptiveLimitConfig{Algorithm: Gradient, InitialLimit: 20, Tolerance: 1})
+
+	for i := 0; i < 10; i++ {
+		a.observe(10*time.Millisecond, 20, false)
+	}
+	steady := a.current()
+	if steady <= 20 {
+		t.Errorf("Expected stable latency under load to raise the limit, got %d", steady)
+	}
+
+	for i := 0; i < 10; i++ {
+		a.observe(100*time.Millisecond, steady, false)
+	}
+	if a.current() >= steady {
+		t.Errorf("Expected rising latency to lower the limit below %d, got %d", steady, a.current())
+	}
+}
+
+func TestAdaptiveLimiter_RaisesLimiterLimit(t *testing.T) {
+	a := newAdaptiveLimiter(AdaptiveLimitConfig{InitialLimit: 1})
+	ctx := context.Background()
+	a.acquire(ctx, PriorityNormal)
+
+	acquired := make(chan struct{})
+	go func() {
+		a.acquire(ctx, PriorityNormal)
+		close(acquired)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	a.observe(time.Millisecond, 1, false)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Error("Expected a raised limit to admit the queued request")
+	}
+}
+
+func TestOverloaded(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	tests := []struct {
+		name     string
+		status   int
+		err      error
+		expected bool
+	}{
+		{name: "ok", status: http.StatusOK},
+		{name: "too many requests", status: http.StatusTooManyRequests, expected: true},
+		{name: "unavailable", status: http.StatusServiceUnavailable, expected: true},
+		{name: "internal error", status: http.StatusInternalServerError},
+		{name: "dial error", err: &url.Error{Op: "Get", URL: "http://api.test", Err: dialErr}, expected: true},
+		{name: "deadline", err: context.DeadlineExceeded, expected: true},
+		{name: "chaos reset", err: dialErr, expected: true},
+		{name: "injected fault", err: ErrInjectedFault},
+		{name: "hook error", err: &hookError{err: errors.New("rejected")}},
+		{name: "response too large", err: &ResponseTooLargeError{Limit: 10}},
+		{name: "transport-level local error", err: &url.Error{Op: "Get", URL: "http://api.test", Err: ErrUnmatchedRequest}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp *http.Response
+			if tt.err == nil {
+				resp = &http.Response{StatusCode: tt.status}
+			}
+			if got := overloaded(context.Background(), resp, tt.err); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
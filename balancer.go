@@ -0,0 +1,311 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoEndpoints is returned when no endpoint is left to try
+var ErrNoEndpoints = errors.New("no endpoints available")
+
+// LoadBalanceStrategy decides which endpoint serves the next request
+type LoadBalanceStrategy int
+
+const (
+	RoundRobin         LoadBalanceStrategy = iota // Cycle through endpoints in order
+	WeightedRoundRobin                            // Cycle through endpoints proportionally to their weight
+	LeastInFlight                                 // Prefer the endpoint with the fewest outstanding requests
+	Failover                                      // Always use the preferred endpoint while it is healthy
+
+	// srvPriorityWeighted picks randomly by weight within the lowest priority group, as SRV records require
+	srvPriorityWeighted LoadBalanceStrategy = -1
+)
+
+// Endpoint is one of several base URLs serving the same API
+type Endpoint struct {
+	URL      string // Base URL, e.g. "https://replica-1.example.com"
+	Weight   int    // Relative share for WeightedRoundRobin (default 1)
+	Priority int    // Lower values are preferred by Failover, ties keep list order
+}
+
+// LoadBalancerConfig defines the configuration for spreading requests across endpoints
+type LoadBalancerConfig struct {
+	Strategy    LoadBalanceStrategy
+	Endpoints   []Endpoint
+	MaxFailures int           // Consecutive failures before an endpoint is ejected (default 3)
+	EjectFor    time.Duration // How long an ejected endpoint is skipped (default 30s)
+}
+
+// WithLoadBalancer spreads requests across several base URLs, replacing the one passed to NewClient.
+// Endpoints failing MaxFailures times in a row (network errors or 5xx responses) are ejected for EjectFor,
+// attempts cancelled by the caller or a winning hedge don't count.
+// Connection failures are retried on the next endpoint, as are other transport errors for idempotent methods
+// and calls carrying an idempotency key (see WithIdempotencyKeys).
+func WithLoadBalancer(config LoadBalancerConfig) Option {
+	return func(c *Client) {
+		c.balancer = newBalancer(config)
+	}
+}
+
+type balancer struct {
+	strategy    LoadBalanceStrategy
+	maxFailures int
+	ejectFor    time.Duration
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+	next      int
+}
+
+type endpoint struct {
+	url          string
+	weight       int
+	priority     int
+	current      int // smooth weighted round-robin state
+	inFlight     int
+	failures     int
+	ejectedUntil time.Time
+}
+
+func newBalancer(config LoadBalancerConfig) *balancer {
+	if config.MaxFailures <= 0 {
+		config.MaxFailures = 3
+	}
+	if config.EjectFor <= 0 {
+		config.EjectFor = 30 * time.Second
+	}
+
+	b := &balancer{
+		strategy:    config.Strategy,
+		maxFailures: config.MaxFailures,
+		ejectFor:    config.EjectFor,
+	}
+	b.setEndpoints(config.Endpoints)
+	return b
+}
+
+// setEndpoints replaces the endpoint list, keeping the health state of known URLs
+func (b *balancer) setEndpoints(endpoints []Endpoint) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	known := make(map[string]*endpoint, len(b.endpoints))
+	for _, e := range b.endpoints {
+		known[e.url] = e
+	}
+
+	updated := make([]*endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		e, exists := known[strings.TrimSuffix(ep.URL, "/")]
+		if !exists {
+			e = &endpoint{url: strings.TrimSuffix(ep.URL, "/")}
+		}
+		e.weight = ep.Weight
+		// SRV weight 0 means picked only when no other target of the priority is left
+		if e.weight <= 0 && b.strategy != srvPriorityWeighted {
+			e.weight = 1
+		}
+		e.priority = ep.Priority
+		updated = append(updated, e)
+	}
+	sort.SliceStable(updated, func(i, j int) bool {
+		return updated[i].priority < updated[j].priority
+	})
+
+	b.endpoints = updated
+}
+
+// do runs send against picked endpoints until one succeeds or retrying is unsafe
+func (b *balancer) do(ctx context.Context, method string, send func(baseURL string) (*http.Response, error)) (*http.Response, error) {
+	tried := make(map[*endpoint]bool)
+	lastErr := ErrNoEndpoints
+
+	for {
+		e := b.pick(tried)
+		if e == nil {
+			return nil, lastErr
+		}
+		tried[e] = true
+
+		resp, err := send(e.url)
+		b.report(ctx, e, resp, err)
+		if err == nil {
+			resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: func() { b.release(e) }}
+			return resp, nil
+		}
+
+		b.release(e)
+		if !retryable(ctx, method, err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+}
+
+// pick selects the next endpoint not in tried, preferring healthy ones
+func (b *balancer) pick(tried map[*endpoint]bool) *endpoint {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	var healthy, ejected []*endpoint
+	for _, e := range b.endpoints {
+		if tried[e] {
+			continue
+		}
+		if now.Before(e.ejectedUntil) {
+			ejected = append(ejected, e)
+		} else {
+			healthy = append(healthy, e)
+		}
+	}
+
+	// With every endpoint ejected, trying one beats failing outright
+	candidates := healthy
+	if len(candidates) == 0 {
+		candidates = ejected
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var chosen *endpoint
+	switch b.strategy {
+	case WeightedRoundRobin:
+		total := 0
+		for _, e := range candidates {
+			e.current += e.weight
+			total += e.weight
+			if chosen == nil || e.current > chosen.current {
+				chosen = e
+			}
+		}
+		chosen.current -= total
+	case LeastInFlight:
+		start := b.next % len(candidates)
+		b.next++
+		for i := range candidates {
+			e := candidates[(start+i)%len(candidates)]
+			if chosen == nil || e.inFlight < chosen.inFlight {
+				chosen = e
+			}
+		}
+	case Failover:
+		chosen = candidates[0]
+	case srvPriorityWeighted:
+		total := 0
+		group := candidates
+		for i, e := range candidates {
+			if e.priority != candidates[0].priority {
+				group = candidates[:i]
+				break
+			}
+			total += e.weight
+		}
+		if total == 0 {
+			chosen = group[rand.IntN(len(group))]
+			break
+		}
+		n := rand.IntN(total)
+		for _, e := range group {
+			if n < e.weight {
+				chosen = e
+				break
+			}
+			n -= e.weight
+		}
+	default:
+		chosen = candidates[b.next%len(candidates)]
+		b.next++
+	}
+
+	chosen.inFlight++
+	return chosen
+}
+
+// report records the outcome of an attempt for passive health tracking
+func (b *balancer) report(ctx context.Context, e *endpoint, resp *http.Response, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case err == nil && resp.StatusCode < http.StatusInternalServerError:
+		e.failures = 0
+	case endpointFault(ctx, resp, err):
+		e.failures++
+		if e.failures >= b.maxFailures {
+			e.ejectedUntil = time.Now().Add(b.ejectFor)
+			e.failures = 0
+		}
+	}
+}
+
+// endpointFault reports whether an attempt failed because of the endpoint: a 5xx response
+// or a network error. Callers giving up, e.g. hedges losing the race, and errors raised
+// by the client itself, like hook errors or a full bulkhead, leave its health untouched.
+func endpointFault(ctx context.Context, resp *http.Response, err error) bool {
+	if err == nil {
+		return resp.StatusCode >= http.StatusInternalServerError
+	}
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || isHookError(err) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func (b *balancer) release(e *endpoint) {
+	b.mu.Lock()
+	e.inFlight--
+	b.mu.Unlock()
+}
+
+// retryable reports whether a failed attempt may be sent again
+func retryable(ctx context.Context, method string, err error) bool {
+	if ctx.Err() != nil || isHookError(err) {
+		return false
+	}
+
+	// Nothing was sent when a host's bulkhead is full, another host may have room
+	var full *BulkheadFullError
+	if errors.As(err, &full) {
+		return full.Host != ""
+	}
+
+	// Nothing reached the server if the connection was never established
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	return idempotent(method) || hasIdempotencyKey(ctx)
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// releaseOnClose calls release once when the body is closed
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
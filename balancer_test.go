@@ -0,0 +1,195 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newNamedServer returns a server that counts hits and answers with its name
+func newNamedServer(t *testing.T, name string, status int) (*httptest.Server, *int32) {
+	t.Helper()
+	hits := new(int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		w.WriteHeader(status)
+		w.Write([]byte(name))
+	}))
+	t.Cleanup(server.Close)
+	return server, hits
+}
+
+// deadURL returns the URL of a server that no longer accepts connections
+func deadURL() string {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	return server.URL
+}
+
+func getBody(t *testing.T, client *Client, path string) (string, int) {
+	t.Helper()
+	resp, err := client.Get(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body), resp.StatusCode
+}
+
+func TestWithLoadBalancer_RoundRobin(t *testing.T) {
+	a, aHits := newNamedServer(t, "a", http.StatusOK)
+	b, bHits := newNamedServer(t, "b", http.StatusOK)
+
+	client := NewClient("", WithLoadBalancer(LoadBalancerConfig{
+		Strategy:  RoundRobin,
+		Endpoints: []Endpoint{{URL: a.URL}, {URL: b.URL}},
+	}))
+
+	var order string
+	for i := 0; i < 4; i++ {
+		body, _ := getBody(t, client, "/")
+		order += body
+	}
+
+	if order != "abab" {
+		t.Errorf("Expected alternating endpoints, got %q", order)
+	}
+	if *aHits != 2 || *bHits != 2 {
+		t.Errorf("Expected 2 hits each, got a=%d b=%d", *aHits, *bHits)
+	}
+}
+
+func TestWithLoadBalancer_Weighted(t *testing.T) {
+	a, aHits := newNamedServer(t, "a", http.StatusOK)
+	b, bHits := newNamedServer(t, "b", http.StatusOK)
+
+	client := NewClient("", WithLoadBalancer(LoadBalancerConfig{
+		Strategy:  WeightedRoundRobin,
+		Endpoints: []Endpoint{{URL: a.URL, Weight: 3}, {URL: b.URL, Weight: 1}},
+	}))
+
+	for i := 0; i < 8; i++ {
+		getBody(t, client, "/")
+	}
+
+	if *aHits != 6 || *bHits != 2 {
+		t.Errorf("Expected a 3:1 split, got a=%d b=%d", *aHits, *bHits)
+	}
+}
+
+func TestWithLoadBalancer_LeastInFlight(t *testing.T) {
+	a, _ := newNamedServer(t, "a", http.StatusOK)
+	b, _ := newNamedServer(t, "b", http.StatusOK)
+
+	client := NewClient("", WithLoadBalancer(LoadBalancerConfig{
+		Strategy:  LeastInFlight,
+		Endpoints: []Endpoint{{URL: a.URL}, {URL: b.URL}},
+	}))
+
+	// Holding a response open keeps its endpoint busy
+	held, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	heldBody, _ := io.ReadAll(held.Body)
+
+	for i := 0; i < 3; i++ {
+		body, _ := getBody(t, client, "/")
+		if body == string(heldBody) {
+			t.Errorf("Expected the idle endpoint, got busy endpoint %q", body)
+		}
+	}
+	held.Body.Close()
+}
+
+func TestWithLoadBalancer_Failover(t *testing.T) {
+	secondary, secondaryHits := newNamedServer(t, "secondary", http.StatusOK)
+
+	client := NewClient("", WithLoadBalancer(LoadBalancerConfig{
+		Strategy: Failover,
+		Endpoints: []Endpoint{
+			{URL: secondary.URL, Priority: 1},
+			{URL: deadURL(), Priority: 0},
+		},
+	}))
+
+	// POST is not idempotent, but a refused connection never reached the server
+	resp, err := client.Post(context.Background(), "/", map[string]string{"k": "v"})
+	if err != nil {
+		t.Fatalf("Expected failover to the secondary, got error: %v", err)
+	}
+	resp.Body.Close()
+
+	if *secondaryHits != 1 {
+		t.Errorf("Expected the secondary to serve the request, got %d hits", *secondaryHits)
+	}
+}
+
+func TestWithLoadBalancer_Ejection(t *testing.T) {
+	bad, badHits := newNamedServer(t, "bad", http.StatusInternalServerError)
+	good, _ := newNamedServer(t, "good", http.StatusOK)
+
+	client := NewClient("", WithLoadBalancer(LoadBalancerConfig{
+		Strategy:    RoundRobin,
+		Endpoints:   []Endpoint{{URL: bad.URL}, {URL: good.URL}},
+		MaxFailures: 2,
+		EjectFor:    time.Minute,
+	}))
+
+	for i := 0; i < 10; i++ {
+		getBody(t, client, "/")
+	}
+
+	if *badHits != 2 {
+		t.Errorf("Expected the failing endpoint to be ejected after 2 failures, got %d hits", *badHits)
+	}
+}
+
+func TestWithLoadBalancer_AllDown(t *testing.T) {
+	client := NewClient("", WithLoadBalancer(LoadBalancerConfig{
+		Endpoints: []Endpoint{{URL: deadURL()}, {URL: deadURL()}},
+	}))
+
+	_, err := client.Get(context.Background(), "/")
+	if err == nil {
+		t.Fatal("Expected error with every endpoint down, got none")
+	}
+	if errors.Is(err, ErrNoEndpoints) {
+		t.Errorf("Expected the last connection error, got %v", err)
+	}
+
+	empty := NewClient("", WithLoadBalancer(LoadBalancerConfig{}))
+	if _, err := empty.Get(context.Background(), "/"); !errors.Is(err, ErrNoEndpoints) {
+		t.Errorf("Expected ErrNoEndpoints, got %v", err)
+	}
+}
+
+func TestWithLoadBalancer_CallerCancellationIsNotAFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := NewClient("", WithLoadBalancer(LoadBalancerConfig{
+		Endpoints:   []Endpoint{{URL: server.URL}},
+		MaxFailures: 1,
+	}))
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		if _, err := client.Get(ctx, "/"); err == nil {
+			t.Fatal("Expected the caller's deadline to be exceeded")
+		}
+		cancel()
+	}
+
+	if e := client.balancer.endpoints[0]; !e.ejectedUntil.IsZero() || e.failures != 0 {
+		t.Errorf("Expected the slow but healthy endpoint to stay healthy, got %d failures", e.failures)
+	}
+}
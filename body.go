@@ -0,0 +1,49 @@
+package httpclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// peekBody reads up to limit bytes of body and returns them along with a
+// replacement body that still yields the full content to later readers
+func peekBody(body io.ReadCloser, limit int) (prefix []byte, truncated bool, replacement io.ReadCloser, err error) {
+	if body == nil || body == http.NoBody {
+		return nil, false, body, nil
+	}
+
+	prefix, err = io.ReadAll(io.LimitReader(body, int64(limit)+1))
+	replacement = &readCloser{
+		Reader: io.MultiReader(bytes.NewReader(prefix), body),
+		Closer: body,
+	}
+	if len(prefix) > limit {
+		return prefix[:limit], true, replacement, err
+	}
+	return prefix, false, replacement, err
+}
+
+// requestBody returns up to limit bytes of the request body without consuming it
+func requestBody(req *http.Request, limit int) (prefix []byte, truncated bool) {
+	if req.GetBody == nil || req.ContentLength == 0 {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	defer body.Close()
+
+	prefix, _ = io.ReadAll(io.LimitReader(body, int64(limit)+1))
+	if len(prefix) > limit {
+		return prefix[:limit], true
+	}
+	return prefix, false
+}
+
+// readCloser combines a reader with the closer of the original body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,274 @@
+package httpclient
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// ErrBulkheadFull matches every BulkheadFullError
+var ErrBulkheadFull = errors.New("bulkhead queue full")
+
+// BulkheadFullError is returned when a request can't run or queue because a limit is saturated
+type BulkheadFullError struct {
+	Host string // Host whose limit is saturated, empty for the client-wide limit
+}
+
+func (e *BulkheadFullError) Error() string {
+	if e.Host == "" {
+		return ErrBulkheadFull.Error()
+	}
+	return ErrBulkheadFull.Error() + " for host " + e.Host
+}
+
+// Is makes errors.Is(err, ErrBulkheadFull) match
+func (e *BulkheadFullError) Is(target error) bool {
+	return target == ErrBulkheadFull
+}
+
+// Priority orders requests waiting for a bulkhead slot, higher priorities run first
+type Priority int
+
+const (
+	PriorityLow    Priority = -1 // Background work such as CachedClient refreshes
+	PriorityNormal Priority = 0  // Default
+	PriorityHigh   Priority = 1  // Interactive calls
+)
+
+// WithPriority sets the priority of a request waiting for a bulkhead or adaptive limit slot
+func WithPriority(priority Priority) RequestOption {
+	return func(req *http.Request) {
+		if state := callStateFrom(req.Context()); state != nil {
+			state.priority = priority
+		}
+	}
+}
+
+// BulkheadConfig defines the configuration for concurrency limiting
+type BulkheadConfig struct {
+	MaxConcurrent int // Maximum requests in flight for the client (0 means unlimited)
+	MaxPerHost    int // Maximum requests in flight per host (0 means unlimited)
+	MaxQueue      int // Maximum requests waiting per limit (0 means unlimited, negative disables queueing)
+}
+
+// WithBulkhead caps the requests in flight, from sending until the response body is closed.
+// Requests over a limit wait by priority until a slot frees up or their context is done,
+// or fail with a BulkheadFullError when the queue is full. The client-wide slot is held for
+// the whole call, including retries on other endpoints and hedges, while per-host slots are
+// taken per attempt so load balanced clients move on to the next host when one is full.
+func WithBulkhead(config BulkheadConfig) Option {
+	return func(c *Client) {
+		c.bulkhead = newBulkhead(config)
+	}
+}
+
+type bulkhead struct {
+	global   *limiter
+	perHost  int
+	maxQueue int
+
+	mu    sync.Mutex
+	hosts map[string]*limiter
+}
+
+func newBulkhead(config BulkheadConfig) *bulkhead {
+	b := &bulkhead{perHost: config.MaxPerHost, maxQueue: config.MaxQueue, hosts: make(map[string]*limiter)}
+	if config.MaxConcurrent > 0 {
+		b.global = newLimiter(config.MaxConcurrent, config.MaxQueue)
+	}
+	return b
+}
+
+// host returns the limiter for host, nil without a per-host limit
+func (b *bulkhead) host(host string) *limiter {
+	if b.perHost <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	l, ok := b.hosts[host]
+	if !ok {
+		l = newLimiter(b.perHost, b.maxQueue)
+		b.hosts[host] = l
+	}
+	return l
+}
+
+// hold takes a client-wide slot for a whole call until the response body is closed
+func (b *bulkhead) hold(ctx context.Context, call func(context.Context) (*http.Response, error)) (*http.Response, error) {
+	priority := PriorityNormal
+	if state := callStateFrom(ctx); state != nil {
+		priority = state.priority
+	}
+	if err := b.global.acquire(ctx, priority); err != nil {
+		return nil, err
+	}
+
+	resp, err := call(ctx)
+	if err != nil {
+		b.global.release()
+		return nil, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: b.global.release}
+	return resp, nil
+}
+
+// bulkheadMiddleware holds a per-host slot per attempt until the response body is closed
+func (c *Client) bulkheadMiddleware() Middleware {
+	b := c.bulkhead
+
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			l := b.host(req.URL.Host)
+			if l == nil {
+				return next(req)
+			}
+
+			ctx := req.Context()
+			priority := PriorityNormal
+			if state := callStateFrom(ctx); state != nil {
+				priority = state.priority
+			}
+			if err := l.acquire(ctx, priority); err != nil {
+				var full *BulkheadFullError
+				if errors.As(err, &full) {
+					full.Host = req.URL.Host
+				}
+				return nil, err
+			}
+
+			resp, err := next(req)
+			if err != nil {
+				l.release()
+				return nil, err
+			}
+			resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: l.release}
+			return resp, nil
+		}
+	}
+}
+
+// limiter is a counting semaphore with a bounded priority queue and an adjustable limit
+type limiter struct {
+	mu       sync.Mutex
+	limit    int
+	inFlight int
+	maxQueue int
+	queue    waitQueue
+	seq      uint64
+}
+
+func newLimiter(limit, maxQueue int) *limiter {
+	return &limiter{limit: limit, maxQueue: maxQueue}
+}
+
+// waiter is a request queued for a slot
+type waiter struct {
+	priority Priority
+	seq      uint64
+	index    int
+	ready    chan struct{}
+	granted  bool
+}
+
+// acquire takes a slot, waiting by priority while the limiter is saturated
+func (l *limiter) acquire(ctx context.Context, priority Priority) error {
+	l.mu.Lock()
+	if l.inFlight < l.limit && l.queue.Len() == 0 {
+		l.inFlight++
+		l.mu.Unlock()
+		return nil
+	}
+	if l.maxQueue < 0 || (l.maxQueue > 0 && l.queue.Len() >= l.maxQueue) {
+		l.mu.Unlock()
+		return &BulkheadFullError{}
+	}
+
+	l.seq++
+	w := &waiter{priority: priority, seq: l.seq, ready: make(chan struct{})}
+	heap.Push(&l.queue, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		if w.granted {
+			// The slot was handed over as the context finished, pass it on
+			l.mu.Unlock()
+			l.release()
+		} else {
+			heap.Remove(&l.queue, w.index)
+			l.mu.Unlock()
+		}
+		return ctx.Err()
+	}
+}
+
+// release frees a slot, handing it to the next waiter if any
+func (l *limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	l.grant()
+}
+
+// inFlightCount returns the slots currently taken
+func (l *limiter) inFlightCount() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+// setLimit changes the limit, starting queued requests if it grew
+func (l *limiter) setLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+	l.grant()
+}
+
+// grant hands free slots to waiters in priority order, must be called with l.mu held
+func (l *limiter) grant() {
+	for l.inFlight < l.limit && l.queue.Len() > 0 {
+		w := heap.Pop(&l.queue).(*waiter)
+		w.granted = true
+		l.inFlight++
+		close(w.ready)
+	}
+}
+
+// waitQueue is a heap of waiters, highest priority first and FIFO within a priority
+type waitQueue []*waiter
+
+func (q waitQueue) Len() int { return len(q) }
+
+func (q waitQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waitQueue) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waitQueue) Pop() interface{} {
+	old := *q
+	w := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return w
+}
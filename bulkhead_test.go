@@ -0,0 +1,240 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithBulkhead_MaxConcurrent(t *testing.T) {
+	var current, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&current, -1)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithBulkhead(BulkheadConfig{MaxConcurrent: 2}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(context.Background(), "/")
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if peak != 2 {
+		t.Errorf("Expected at most 2 requests in flight, got %d", peak)
+	}
+}
+
+func TestWithBulkhead_QueueFull(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithBulkhead(BulkheadConfig{MaxConcurrent: 1, MaxQueue: -1}))
+
+	// The slot is held until the body is closed
+	held, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err = client.Get(context.Background(), "/")
+	var full *BulkheadFullError
+	if !errors.Is(err, ErrBulkheadFull) || !errors.As(err, &full) || full.Host != "" {
+		t.Fatalf("Expected a client-wide BulkheadFullError, got %v", err)
+	}
+
+	held.Body.Close()
+	resp, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Expected the slot to be released, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestWithBulkhead_PerHost(t *testing.T) {
+	a, _ := newNamedServer(t, "a", http.StatusOK)
+	b, _ := newNamedServer(t, "b", http.StatusOK)
+
+	client := NewClient("", WithBulkhead(BulkheadConfig{MaxPerHost: 1, MaxQueue: -1}))
+	ctx := context.Background()
+
+	heldA, err := client.Get(ctx, a.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer heldA.Body.Close()
+
+	respB, err := client.Get(ctx, b.URL)
+	if err != nil {
+		t.Fatalf("Expected other hosts to be unaffected, got %v", err)
+	}
+	respB.Body.Close()
+
+	_, err = client.Get(ctx, a.URL)
+	var full *BulkheadFullError
+	if !errors.As(err, &full) || full.Host != a.Listener.Addr().String() {
+		t.Errorf("Expected a BulkheadFullError for host a, got %v", err)
+	}
+}
+
+func TestWithBulkhead_QueueDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithBulkhead(BulkheadConfig{MaxConcurrent: 1}))
+
+	held, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.Get(ctx, "/"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the queued request to time out, got %v", err)
+	}
+
+	held.Body.Close()
+	resp, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Expected the timed out waiter to leave the queue, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestLimiter_Priority(t *testing.T) {
+	l := newLimiter(1, 0)
+	ctx := context.Background()
+	if err := l.acquire(ctx, PriorityNormal); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []Priority
+	var wg sync.WaitGroup
+	for _, p := range []Priority{PriorityLow, PriorityNormal, PriorityHigh} {
+		wg.Add(1)
+		go func(p Priority) {
+			defer wg.Done()
+			if err := l.acquire(ctx, p); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			mu.Lock()
+			order = append(order, p)
+			mu.Unlock()
+			l.release()
+		}(p)
+		// Queue them in a known order
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	l.release()
+	wg.Wait()
+
+	if len(order) != 3 || order[0] != PriorityHigh || order[1] != PriorityNormal || order[2] != PriorityLow {
+		t.Errorf("Expected high, normal, low, got %v", order)
+	}
+}
+
+func TestLimiter_SetLimit(t *testing.T) {
+	l := newLimiter(1, 0)
+	ctx := context.Background()
+	l.acquire(ctx, PriorityNormal)
+
+	acquired := make(chan struct{})
+	go func() {
+		l.acquire(ctx, PriorityNormal)
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Expected the second acquire to wait")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	l.setLimit(2)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Error("Expected raising the limit to start the queued request")
+	}
+}
+
+func TestWithBulkhead_LoadBalancer(t *testing.T) {
+	a, aHits := newNamedServer(t, "a", http.StatusOK)
+	b, bHits := newNamedServer(t, "b", http.StatusOK)
+	endpoints := []Endpoint{{URL: a.URL}, {URL: b.URL}}
+	ctx := context.Background()
+
+	t.Run("client-wide limit", func(t *testing.T) {
+		client := NewClient("",
+			WithLoadBalancer(LoadBalancerConfig{Endpoints: endpoints, MaxFailures: 1}),
+			WithBulkhead(BulkheadConfig{MaxConcurrent: 1, MaxQueue: -1}),
+		)
+		held, err := client.Get(ctx, "/")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer held.Body.Close()
+		before := atomic.LoadInt32(aHits) + atomic.LoadInt32(bHits)
+
+		for i := 0; i < 3; i++ {
+			if _, err := client.Get(ctx, "/"); !errors.Is(err, ErrBulkheadFull) {
+				t.Errorf("Expected ErrBulkheadFull, got %v", err)
+			}
+		}
+
+		if after := atomic.LoadInt32(aHits) + atomic.LoadInt32(bHits); after != before {
+			t.Errorf("Expected rejected calls not to reach any endpoint, got %d hits", after-before)
+		}
+		for _, e := range client.balancer.endpoints {
+			if !e.ejectedUntil.IsZero() {
+				t.Errorf("Expected %s not to be ejected for a full bulkhead", e.url)
+			}
+		}
+	})
+
+	t.Run("per-host limit", func(t *testing.T) {
+		client := NewClient("",
+			WithLoadBalancer(LoadBalancerConfig{Strategy: Failover, Endpoints: endpoints, MaxFailures: 1}),
+			WithBulkhead(BulkheadConfig{MaxPerHost: 1, MaxQueue: -1}),
+		)
+		held, err := client.Get(ctx, "/")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer held.Body.Close()
+
+		if body, _ := getBody(t, client, "/"); body != "b" {
+			t.Errorf("Expected a full host to move the request to the next one, got %q", body)
+		}
+		if !client.balancer.endpoints[0].ejectedUntil.IsZero() {
+			t.Error("Expected the full host not to be ejected")
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/codes"
 	"io"
 	"net/http"
 	"strings"
@@ -18,6 +19,7 @@ type CacheConfig struct {
 	CronSpec         string        // Cron specification for updates
 	Expiration       time.Duration // How long the cache is valid
 	SkipInitialFetch bool          // Skip the initial fetch on startup
+	MaxResponseBytes int64         // Response body limit (0 uses the client limit, negative disables it)
 }
 
 // CachedClient extends our base HTTP client with caching capabilities
@@ -34,6 +36,8 @@ type CacheEntry struct {
 	Data       interface{}
 	UpdatedAt  time.Time
 	Expiration time.Duration
+
+	maxResponseBytes int64
 }
 
 func NewCachedClient(baseURL string, opts ...Option) *CachedClient {
@@ -54,6 +58,8 @@ func (c *CachedClient) SetupCachedEndpoint(ctx context.Context, config CacheConf
 		Data:       result,
 		Expiration: config.Expiration,
 		UpdatedAt:  time.Time{}, // Zero time to force fetch on first GetCachedOrFetch
+
+		maxResponseBytes: config.MaxResponseBytes,
 	}
 	c.cacheMux.Unlock()
 
@@ -67,8 +73,9 @@ func (c *CachedClient) SetupCachedEndpoint(ctx context.Context, config CacheConf
 		default:
 			updateCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 			defer cancel()
-			if err := c.updateCache(updateCtx, config.Path, result); err != nil {
-				fmt.Printf("Error updating cache for %s: %v\n", config.Path, err)
+			// Scheduled refreshes yield to interactive calls waiting for a bulkhead slot
+			if err := c.updateCache(updateCtx, config.Path, result, WithPriority(PriorityLow)); err != nil {
+				c.log().ErrorContext(updateCtx, "cache refresh failed", "path", config.Path, "error", err)
 			}
 		}
 	}
@@ -136,8 +143,24 @@ func (c *CachedClient) Stop() {
 }
 
 // updateCache fetches fresh data from the endpoint and updates the cache
-func (c *CachedClient) updateCache(ctx context.Context, path string, result interface{}) error {
-	resp, err := c.Get(ctx, path)
+func (c *CachedClient) updateCache(ctx context.Context, path string, result interface{}, opts ...RequestOption) (err error) {
+	ctx, span := c.startCacheSpan(ctx, path)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+		c.metrics.CacheRefreshed(path, err)
+	}()
+
+	c.cacheMux.RLock()
+	if entry, exists := c.cache[path]; exists && entry.maxResponseBytes != 0 {
+		opts = append([]RequestOption{WithRequestMaxResponseBytes(entry.maxResponseBytes)}, opts...)
+	}
+	c.cacheMux.RUnlock()
+
+	resp, err := c.Get(ctx, path, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to fetch data: %w", err)
 	}
@@ -173,24 +196,31 @@ func (c *CachedClient) GetCachedOrFetch(ctx context.Context, path string) (inter
 	c.cacheMux.RUnlock()
 
 	if !exists {
+		c.metrics.CacheMiss(path)
 		return nil, fmt.Errorf("no cache entry for path: %s", path)
 	}
 
 	// Always fetch if the cache has never been updated
 	// or if the cache is expired
-	needsFetch := entry.UpdatedAt.IsZero() || time.Since(entry.UpdatedAt) > entry.Expiration
-
-	if needsFetch {
-		if err := c.updateCache(ctx, path, entry.Data); err != nil {
-			return nil, fmt.Errorf("failed to fetch fresh data: %w", err)
-		}
+	switch {
+	case entry.UpdatedAt.IsZero():
+		c.metrics.CacheMiss(path)
+	case time.Since(entry.UpdatedAt) > entry.Expiration:
+		c.metrics.CacheStale(path)
+	default:
+		c.metrics.CacheHit(path)
+		return entry.Data, nil
+	}
 
-		// Re-get the updated cache entry
-		c.cacheMux.RLock()
-		entry = c.cache[path]
-		c.cacheMux.RUnlock()
+	if err := c.updateCache(ctx, path, entry.Data); err != nil {
+		return nil, fmt.Errorf("failed to fetch fresh data: %w", err)
 	}
 
+	// Re-get the updated cache entry
+	c.cacheMux.RLock()
+	entry = c.cache[path]
+	c.cacheMux.RUnlock()
+
 	return entry.Data, nil
 }
 
@@ -201,13 +231,16 @@ func (c *CachedClient) GetCached(path string) (interface{}, error) {
 	c.cacheMux.RUnlock()
 
 	if !exists {
+		c.metrics.CacheMiss(path)
 		return nil, fmt.Errorf("no cache entry for path: %s", path)
 	}
 
 	if time.Since(entry.UpdatedAt) > entry.Expiration {
+		c.metrics.CacheStale(path)
 		return entry.Data, fmt.Errorf("cache expired for path: %s", path)
 	}
 
+	c.metrics.CacheHit(path)
 	return entry.Data, nil
 }
 
@@ -0,0 +1,320 @@
+package httpclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+// CassetteMode selects whether a cassette records or replays traffic
+type CassetteMode int
+
+const (
+	ModeReplay CassetteMode = iota // Serve requests from the cassette file
+	ModeRecord                     // Send requests and save every exchange to the cassette file
+)
+
+// Matcher reports whether a recorded request matches an outgoing one
+type Matcher func(req *http.Request, body []byte, recorded RecordedRequest) bool
+
+// RecordedRequest is the request half of a saved exchange
+type RecordedRequest struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Headers      http.Header `json:"headers,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+// RecordedResponse is the response half of a saved exchange
+type RecordedResponse struct {
+	Status       int         `json:"status"`
+	Headers      http.Header `json:"headers,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+// Interaction is one line of a cassette file
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// MatchMethod matches requests with the same method
+func MatchMethod(req *http.Request, _ []byte, recorded RecordedRequest) bool {
+	return req.Method == recorded.Method
+}
+
+// MatchURL matches requests with the same full URL, including the query
+func MatchURL(req *http.Request, _ []byte, recorded RecordedRequest) bool {
+	return req.URL.String() == recorded.URL
+}
+
+// MatchBody matches requests with the same body
+func MatchBody(_ *http.Request, body []byte, recorded RecordedRequest) bool {
+	return bytes.Equal(body, recorded.body())
+}
+
+// MatchHeaders matches requests with the same values for the given headers.
+// Sensitive headers are redacted when recording and can't be matched.
+func MatchHeaders(names ...string) Matcher {
+	return func(req *http.Request, _ []byte, recorded RecordedRequest) bool {
+		for _, name := range names {
+			if req.Header.Get(name) != recorded.Headers.Get(name) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// ErrUnmatchedRequest is returned by strict cassettes for requests without a recording
+var ErrUnmatchedRequest = errors.New("no recorded interaction matches request")
+
+// CassetteConfig defines the configuration for record/replay testing
+type CassetteConfig struct {
+	Path     string       // JSON Lines file holding one Interaction per line
+	Mode     CassetteMode // ModeReplay or ModeRecord
+	Matchers []Matcher    // All must match for a recording to be replayed (default method and URL)
+	Strict   bool         // Fail unmatched requests in replay mode instead of sending them
+}
+
+// Cassette records exchanges to a file or replays them without the network
+type Cassette struct {
+	config CassetteConfig
+	redact *redactor
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewCassette loads the cassette file in replay mode, or truncates it in record mode
+func NewCassette(config CassetteConfig) (*Cassette, error) {
+	if len(config.Matchers) == 0 {
+		config.Matchers = []Matcher{MatchMethod, MatchURL}
+	}
+	c := &Cassette{config: config, redact: newRedactor(nil, nil)}
+
+	if config.Mode == ModeRecord {
+		if err := os.WriteFile(config.Path, nil, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to create cassette: %w", err)
+		}
+		return c, nil
+	}
+
+	f, err := os.Open(config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64<<10), 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+			return nil, fmt.Errorf("invalid cassette line %d: %w", line, err)
+		}
+		c.interactions = append(c.interactions, interaction)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	c.used = make([]bool, len(c.interactions))
+
+	return c, nil
+}
+
+// WithCassette records or replays all traffic through cassette
+func WithCassette(cassette *Cassette) Option {
+	return func(c *Client) {
+		c.transportWrappers = append(c.transportWrappers, func(next http.RoundTripper) http.RoundTripper {
+			return &cassetteTransport{next: next, cassette: cassette, limit: c.responseLimit}
+		})
+	}
+}
+
+type cassetteTransport struct {
+	next     http.RoundTripper
+	cassette *Cassette
+	limit    func(context.Context) int64 // Response body limit of the client, 0 if unlimited
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.cassette.config.Mode == ModeRecord {
+		return t.cassette.record(t.next, req, body, t.limit(req.Context()))
+	}
+
+	if interaction, ok := t.cassette.match(req, body); ok {
+		return interaction.Response.toResponse(req), nil
+	}
+	if t.cassette.config.Strict {
+		return nil, fmt.Errorf("%w: %s %s", ErrUnmatchedRequest, req.Method, req.URL)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// match returns the first unused matching interaction, reusing played ones once all were used
+func (c *Cassette) match(req *http.Request, body []byte) (Interaction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	reuse := -1
+	for i, interaction := range c.interactions {
+		if !c.matches(req, body, interaction.Request) {
+			continue
+		}
+		if !c.used[i] {
+			c.used[i] = true
+			return interaction, true
+		}
+		if reuse < 0 {
+			reuse = i
+		}
+	}
+	if reuse >= 0 {
+		return c.interactions[reuse], true
+	}
+	return Interaction{}, false
+}
+
+func (c *Cassette) matches(req *http.Request, body []byte, recorded RecordedRequest) bool {
+	for _, matcher := range c.config.Matchers {
+		if !matcher(req, body, recorded) {
+			return false
+		}
+	}
+	return true
+}
+
+// record sends the request and appends the exchange to the cassette file.
+// Responses over limit fail without being recorded.
+func (c *Cassette) record(next http.RoundTripper, req *http.Request, body []byte, limit int64) (*http.Response, error) {
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := io.Reader(resp.Body)
+	if limit > 0 {
+		reader = io.LimitReader(resp.Body, limit+1)
+	}
+	respBody, err := io.ReadAll(reader)
+	resp.Body.Close()
+	if err == nil && limit > 0 && int64(len(respBody)) > limit {
+		err = &ResponseTooLargeError{Limit: limit}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request:  RecordedRequest{Method: req.Method, URL: req.URL.String(), Headers: c.redact.header(req.Header)},
+		Response: RecordedResponse{Status: resp.StatusCode, Headers: c.redact.header(resp.Header)},
+	}
+	interaction.Request.Body, interaction.Request.BodyEncoding = encodeBody(body)
+	interaction.Response.Body, interaction.Response.BodyEncoding = encodeBody(respBody)
+
+	line, err := json.Marshal(interaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal interaction: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, err := os.OpenFile(c.config.Path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write cassette: %w", err)
+	}
+	c.interactions = append(c.interactions, interaction)
+
+	return resp, nil
+}
+
+func (r RecordedRequest) body() []byte {
+	return decodeBody(r.Body, r.BodyEncoding)
+}
+
+// toResponse rebuilds the recorded response for req
+func (r RecordedResponse) toResponse(req *http.Request) *http.Response {
+	body := decodeBody(r.Body, r.BodyEncoding)
+	header := r.Headers.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(r.Status) + " " + http.StatusText(r.Status),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// readRequestBody returns the full request body without consuming it
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// encodeBody keeps text bodies readable and base64-encodes binary ones
+func encodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func decodeBody(body, encoding string) []byte {
+	if encoding == "base64" {
+		decoded, _ := base64.StdEncoding.DecodeString(body)
+		return decoded
+	}
+	return []byte(body)
+}
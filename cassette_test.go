@@ -0,0 +1,203 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// redirectDialer connects every request to server regardless of the requested host
+func redirectDialer(server *httptest.Server) DialFunc {
+	return func(ctx context.Context, network, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, network, server.Listener.Addr().String())
+	}
+}
+
+func recordCassette(t *testing.T, path string, handler http.HandlerFunc, calls func(*Client)) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	cassette, err := NewCassette(CassetteConfig{Path: path, Mode: ModeRecord})
+	if err != nil {
+		t.Fatalf("Failed to create cassette: %v", err)
+	}
+	// Replays must not depend on the recording server's address
+	client := NewClient("http://api.test", WithCassette(cassette), WithDialer(redirectDialer(server)))
+	calls(client)
+}
+
+// readBody returns a helper that reads the whole body of a successful response
+func readBody(t *testing.T) func(*http.Response, error) string {
+	return func(resp *http.Response, err error) string {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+}
+
+func TestCassette_RecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.jsonl")
+
+	recordCassette(t, path, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Served-By", "origin")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		w.Write([]byte(r.Method + " " + r.URL.Path))
+	}, func(client *Client) {
+		ctx := context.Background()
+		readBody(t)(client.Get(ctx, "/users", WithRequestHeader("Authorization", "Bearer secret")))
+		readBody(t)(client.Post(ctx, "/users", map[string]string{"name": "alice"}))
+	})
+
+	raw, _ := os.ReadFile(path)
+	if lines := strings.Count(string(raw), "\n"); lines != 2 {
+		t.Fatalf("Expected 2 recorded lines, got %d", lines)
+	}
+	if strings.Contains(string(raw), "Bearer secret") {
+		t.Error("Expected the Authorization header to be redacted in the cassette")
+	}
+
+	cassette, err := NewCassette(CassetteConfig{Path: path, Mode: ModeReplay, Strict: true})
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	client := NewClient("http://api.test", WithCassette(cassette))
+
+	resp, err := client.Post(context.Background(), "/users", map[string]string{"name": "alice"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("X-Served-By") != "origin" {
+		t.Errorf("Expected recorded status and headers, got %d %v", resp.StatusCode, resp.Header)
+	}
+	if body := readBody(t)(resp, nil); body != "POST /users" {
+		t.Errorf("Expected recorded body, got %q", body)
+	}
+
+	if body := readBody(t)(client.Get(context.Background(), "/users")); body != "GET /users" {
+		t.Errorf("Expected recorded body, got %q", body)
+	}
+}
+
+func TestCassette_Strict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.jsonl")
+	os.WriteFile(path, nil, 0o644)
+
+	cassette, err := NewCassette(CassetteConfig{Path: path, Strict: true})
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	client := NewClient("http://api.test", WithCassette(cassette))
+
+	if _, err := client.Get(context.Background(), "/unknown"); !errors.Is(err, ErrUnmatchedRequest) {
+		t.Errorf("Expected ErrUnmatchedRequest, got %v", err)
+	}
+}
+
+func TestCassette_Passthrough(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write([]byte("live"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "empty.jsonl")
+	os.WriteFile(path, nil, 0o644)
+
+	cassette, _ := NewCassette(CassetteConfig{Path: path})
+	client := NewClient(server.URL, WithCassette(cassette))
+
+	if body := readBody(t)(client.Get(context.Background(), "/")); body != "live" {
+		t.Errorf("Expected unmatched request to reach the server, got %q", body)
+	}
+	if hits != 1 {
+		t.Errorf("Expected 1 live request, got %d", hits)
+	}
+}
+
+func TestCassette_Matchers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.jsonl")
+
+	recordCassette(t, path, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte("results for " + string(body) + " in " + r.Header.Get("Accept-Language")))
+	}, func(client *Client) {
+		ctx := context.Background()
+		readBody(t)(client.Post(ctx, "/search", "go", WithRequestHeader("Accept-Language", "en")))
+		readBody(t)(client.Post(ctx, "/search", "rust", WithRequestHeader("Accept-Language", "en")))
+		readBody(t)(client.Post(ctx, "/search", "go", WithRequestHeader("Accept-Language", "de")))
+	})
+
+	cassette, err := NewCassette(CassetteConfig{
+		Path:     path,
+		Strict:   true,
+		Matchers: []Matcher{MatchMethod, MatchURL, MatchBody, MatchHeaders("Accept-Language")},
+	})
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	client := NewClient("http://api.test", WithCassette(cassette))
+	ctx := context.Background()
+
+	if body := readBody(t)(client.Post(ctx, "/search", "go", WithRequestHeader("Accept-Language", "de"))); body != `results for "go" in de` {
+		t.Errorf("Unexpected replay %q", body)
+	}
+	if body := readBody(t)(client.Post(ctx, "/search", "rust", WithRequestHeader("Accept-Language", "en"))); body != `results for "rust" in en` {
+		t.Errorf("Unexpected replay %q", body)
+	}
+	if _, err := client.Post(ctx, "/search", "zig", WithRequestHeader("Accept-Language", "en")); !errors.Is(err, ErrUnmatchedRequest) {
+		t.Errorf("Expected unmatched body to fail, got %v", err)
+	}
+}
+
+func TestCassette_RedactsResponseHeaders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "login.jsonl")
+
+	recordCassette(t, path, func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "SECRET123"})
+	}, func(client *Client) {
+		readBody(t)(client.Post(context.Background(), "/login", nil))
+	})
+
+	raw, _ := os.ReadFile(path)
+	if strings.Contains(string(raw), "SECRET123") {
+		t.Error("Expected the Set-Cookie header to be redacted in the cassette")
+	}
+}
+
+func TestCassette_RecordMaxResponseBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, 1<<20))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "large.jsonl")
+	cassette, err := NewCassette(CassetteConfig{Path: path, Mode: ModeRecord})
+	if err != nil {
+		t.Fatalf("Failed to create cassette: %v", err)
+	}
+	client := NewClient(server.URL, WithCassette(cassette), WithMaxResponseBytes(100))
+
+	if _, err := client.Get(context.Background(), "/"); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge, got %v", err)
+	}
+	if raw, _ := os.ReadFile(path); len(raw) != 0 {
+		t.Errorf("Expected nothing to be recorded, got %d bytes", len(raw))
+	}
+}
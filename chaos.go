@@ -0,0 +1,201 @@
+package httpclient
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ErrInjectedFault is returned for requests failed by a chaos rule's ErrorRate
+var ErrInjectedFault = errors.New("injected fault")
+
+// ChaosRule defines the faults injected into requests whose path matches Path.
+// Rates are probabilities between 0 and 1, checked in the order of the fields below.
+type ChaosRule struct {
+	Path         string        // path.Match pattern like "/users/*" (empty matches every path)
+	Latency      time.Duration // Delay added before sending the request
+	Jitter       time.Duration // Random extra delay up to this duration
+	ErrorRate    float64       // Fail with ErrInjectedFault without sending the request
+	ResetRate    float64       // Fail with a connection reset (ECONNRESET) without sending the request
+	StatusRate   float64       // Answer with one of StatusCodes without sending the request
+	StatusCodes  []int         // Status codes to pick from (default 503)
+	TruncateRate float64       // Cut the response body in half and end it with io.ErrUnexpectedEOF
+}
+
+// ChaosConfig defines the configuration for fault injection
+type ChaosConfig struct {
+	Rules []ChaosRule // The first rule matching the request path applies
+	Seed  int64       // Seed for reproducible faults (default time based)
+}
+
+// Chaos injects faults into requests for resilience testing. It is enabled when created.
+//
+// Usage:
+//
+//	chaos := httpclient.NewChaos(httpclient.ChaosConfig{Seed: 42, Rules: rules})
+//	client := httpclient.NewClient(url, httpclient.WithChaos(chaos))
+type Chaos struct {
+	rules   []ChaosRule
+	enabled atomic.Bool
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewChaos creates an enabled fault injector
+func NewChaos(config ChaosConfig) *Chaos {
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	ch := &Chaos{rules: config.Rules, rng: rand.New(rand.NewSource(seed))}
+	ch.enabled.Store(true)
+	return ch
+}
+
+// WithChaos injects faults innermost, directly around sending the request, so tracing,
+// metrics, logging, concurrency limits and all registered middleware observe them
+func WithChaos(ch *Chaos) Option {
+	return func(c *Client) {
+		c.chaos = ch
+	}
+}
+
+// Enable turns fault injection on
+func (ch *Chaos) Enable() {
+	ch.enabled.Store(true)
+}
+
+// Disable turns fault injection off, requests pass through untouched
+func (ch *Chaos) Disable() {
+	ch.enabled.Store(false)
+}
+
+// Enabled reports whether faults are being injected
+func (ch *Chaos) Enabled() bool {
+	return ch.enabled.Load()
+}
+
+// Middleware returns the middleware injecting faults. Registered with WithMiddleware it runs
+// outside the built-in tracing, metrics, logging and concurrency limits, use WithChaos to reach them.
+func (ch *Chaos) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			if !ch.Enabled() {
+				return next(req)
+			}
+			rule, ok := ch.match(req.URL.Path)
+			if !ok {
+				return next(req)
+			}
+			return ch.inject(rule, req, next)
+		}
+	}
+}
+
+func (ch *Chaos) match(p string) (ChaosRule, bool) {
+	for _, rule := range ch.rules {
+		if rule.Path == "" {
+			return rule, true
+		}
+		if ok, _ := path.Match(rule.Path, p); ok {
+			return rule, true
+		}
+	}
+	return ChaosRule{}, false
+}
+
+func (ch *Chaos) inject(rule ChaosRule, req *http.Request, next Handler) (*http.Response, error) {
+	if delay := rule.Latency + ch.jitter(rule.Jitter); delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	if ch.roll(rule.ErrorRate) {
+		return nil, ErrInjectedFault
+	}
+	if ch.roll(rule.ResetRate) {
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	}
+	if ch.roll(rule.StatusRate) {
+		return statusResponse(req, ch.pick(rule.StatusCodes)), nil
+	}
+
+	resp, err := next(req)
+	if err != nil || !ch.roll(rule.TruncateRate) {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body[:len(body)/2]), errReader{io.ErrUnexpectedEOF}))
+	return resp, nil
+}
+
+func (ch *Chaos) roll(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.rng.Float64() < rate
+}
+
+func (ch *Chaos) jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return time.Duration(ch.rng.Int63n(int64(max)))
+}
+
+func (ch *Chaos) pick(codes []int) int {
+	if len(codes) == 0 {
+		return http.StatusServiceUnavailable
+	}
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return codes[ch.rng.Intn(len(codes))]
+}
+
+// statusResponse builds an empty response with the given status
+func statusResponse(req *http.Request, status int) *http.Response {
+	return &http.Response{
+		Status:     strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode: status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Body:       http.NoBody,
+		Request:    req,
+	}
+}
+
+// errReader fails every read with err
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
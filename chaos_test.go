@@ -0,0 +1,158 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestChaos_Faults(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer server.Close()
+
+	chaos := NewChaos(ChaosConfig{Rules: []ChaosRule{
+		{Path: "/error", ErrorRate: 1},
+		{Path: "/reset", ResetRate: 1},
+		{Path: "/status/*", StatusRate: 1, StatusCodes: []int{http.StatusTooManyRequests}},
+		{Path: "/truncate", TruncateRate: 1},
+	}})
+	client := NewClient(server.URL, WithMiddleware(chaos.Middleware()))
+	ctx := context.Background()
+
+	if _, err := client.Get(ctx, "/error"); !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("Expected injected fault, got %v", err)
+	}
+	if _, err := client.Get(ctx, "/reset"); !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("Expected connection reset, got %v", err)
+	}
+
+	resp, err := client.Get(ctx, "/status/1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected injected status 429, got %d", resp.StatusCode)
+	}
+
+	if hits != 0 {
+		t.Errorf("Expected injected failures to skip the server, got %d hits", hits)
+	}
+
+	resp, err = client.Get(ctx, "/truncate")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !errors.Is(err, io.ErrUnexpectedEOF) || len(body) != 50 {
+		t.Errorf("Expected half the body and an unexpected EOF, got %d bytes and %v", len(body), err)
+	}
+
+	resp, err = client.Get(ctx, "/untouched")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if len(body) != 100 {
+		t.Errorf("Expected unmatched paths to pass through, got %d bytes", len(body))
+	}
+}
+
+func TestChaos_Latency(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	chaos := NewChaos(ChaosConfig{Rules: []ChaosRule{{Latency: 50 * time.Millisecond}}})
+	client := NewClient(server.URL, WithMiddleware(chaos.Middleware()))
+
+	start := time.Now()
+	resp, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected injected latency, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.Get(ctx, "/"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected latency to respect the deadline, got %v", err)
+	}
+}
+
+func TestChaos_SeededAndSwitchable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	outcomes := func(chaos *Chaos) string {
+		client := NewClient(server.URL, WithMiddleware(chaos.Middleware()))
+		var b strings.Builder
+		for i := 0; i < 20; i++ {
+			resp, err := client.Get(context.Background(), "/")
+			if err != nil {
+				b.WriteByte('x')
+				continue
+			}
+			resp.Body.Close()
+			b.WriteByte('.')
+		}
+		return b.String()
+	}
+
+	config := ChaosConfig{Seed: 42, Rules: []ChaosRule{{ErrorRate: 0.5}}}
+	first, second := outcomes(NewChaos(config)), outcomes(NewChaos(config))
+	if first != second {
+		t.Errorf("Expected the same seed to inject the same faults, got %s and %s", first, second)
+	}
+	if !strings.Contains(first, "x") || !strings.Contains(first, ".") {
+		t.Errorf("Expected a mix of faults and successes, got %s", first)
+	}
+
+	chaos := NewChaos(config)
+	chaos.Disable()
+	if got := outcomes(chaos); strings.Contains(got, "x") {
+		t.Errorf("Expected no faults while disabled, got %s", got)
+	}
+	chaos.Enable()
+	if !chaos.Enabled() {
+		t.Error("Expected chaos to be enabled again")
+	}
+}
+
+func TestWithChaos_VisibleToInternalMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	metrics := &recordingMetrics{}
+	chaos := NewChaos(ChaosConfig{Seed: 1, Rules: []ChaosRule{{ErrorRate: 1}}})
+	client := NewClient(server.URL, WithMetrics(metrics), WithChaos(chaos))
+
+	if _, err := client.Get(context.Background(), "/"); !errors.Is(err, ErrInjectedFault) {
+		t.Fatalf("Expected ErrInjectedFault, got %v", err)
+	}
+
+	var finished []string
+	for _, event := range metrics.snapshot() {
+		if strings.HasPrefix(event, "finish ") {
+			finished = append(finished, event)
+		}
+	}
+	if len(finished) != 1 || !strings.HasSuffix(finished[0], " true") {
+		t.Errorf("Expected metrics to record one failed request, got %v", finished)
+	}
+}
@@ -4,17 +4,70 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/publicsuffix"
 )
 
 type Client struct {
-	client  *http.Client
-	baseURL string
-	headers map[string]string
+	client       *http.Client
+	transport    *http.Transport
+	dialer       *net.Dialer
+	dial         DialFunc
+	unixSocket   string
+	dnsCache     *dnsCache
+	balancer     *balancer
+	hedger       *hedger
+	singleflight *singleflight
+	bulkhead     *bulkhead
+	adaptive     *adaptiveLimiter
+	chaos        *Chaos
+
+	maxResponseBytes int64
+	compression      CompressionConfig
+	decompress       bool
+
+	idempotencyKeys func() string
+	discovery       *srvDiscovery
+	baseURL         string
+	headers         map[string]string
+
+	proxyFunc ProxyFunc
+	proxyAuth *url.Userinfo
+	noProxy   []noProxyRule
+
+	discoveryConfig DiscoveryConfig
+
+	transportWrappers []func(http.RoundTripper) http.RoundTripper
+	baseTransport     http.RoundTripper // Transport below the wrappers, which owns the connections
+
+	middleware []Middleware
+	internal   []Middleware
+	handler    Handler
+
+	logger    *slog.Logger
+	logConfig LogConfig
+
+	curlConfig  CurlConfig
+	debugConfig DebugConfig
+
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+
+	metrics Metrics
+
+	hooksMux    sync.RWMutex
+	beforeHooks []BeforeRequestHook
+	afterHooks  []AfterResponseHook
 }
 
 type Option func(*Client)
@@ -59,11 +112,20 @@ func WithRequestHeader(key, value string) RequestOption {
 
 // NewClient creates a new HTTP client with default configurations
 func NewClient(baseURL string, opts ...Option) *Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	transport := newTransport(dialer)
+
 	c := &Client{
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: transport,
 		},
-		baseURL: baseURL,
+		transport: transport,
+		dialer:    dialer,
+		baseURL:   baseURL,
 		headers: map[string]string{
 			"Content-Type": "application/json",
 		},
@@ -73,24 +135,144 @@ func NewClient(baseURL string, opts ...Option) *Client {
 		opt(c)
 	}
 
+	dial := c.dial
+	if dial == nil {
+		dial = c.dialer.DialContext
+	}
+	if c.dnsCache != nil && c.unixSocket == "" {
+		dial = c.dnsCache.wrap(dial)
+	}
+	c.transport.DialContext = dial
+
+	if strings.HasPrefix(baseURL, "srv+") {
+		c.discovery = newSRVDiscovery(baseURL, c.discoveryConfig)
+		c.balancer = c.discovery.balancer
+	}
+
+	if c.proxyFunc != nil || c.proxyAuth != nil || len(c.noProxy) > 0 {
+		c.transport.Proxy = c.proxy
+	}
+
+	if hook := c.curlConfig.Hook; hook != nil {
+		redact := c.curlRedactor()
+		c.transportWrappers = append(c.transportWrappers, func(next http.RoundTripper) http.RoundTripper {
+			return &curlTransport{next: next, hook: hook, redact: redact}
+		})
+	}
+
+	c.baseTransport = c.client.Transport
+	// Only installed when needed, http.Client cancels unknown transports the legacy way
+	if c.compression.Encoding != "" || c.decompress {
+		c.client.Transport = &compressionTransport{next: c.client.Transport, config: c.compression, decompress: c.decompress}
+	}
+	for _, wrap := range c.transportWrappers {
+		c.client.Transport = wrap(c.client.Transport)
+	}
+
+	if c.tracer != nil {
+		c.internal = append(c.internal, c.tracingMiddleware())
+	}
+	if c.metrics != nil {
+		c.internal = append(c.internal, c.metricsMiddleware())
+	} else {
+		c.metrics = nopMetrics{}
+	}
+	if c.logger != nil {
+		c.internal = append(c.internal, c.loggingMiddleware())
+	}
+	if c.bulkhead != nil && c.bulkhead.perHost > 0 {
+		c.internal = append(c.internal, c.bulkheadMiddleware())
+	}
+	if c.adaptive != nil {
+		c.internal = append(c.internal, c.adaptiveMiddleware())
+	}
+	if c.chaos != nil {
+		c.internal = append(c.internal, c.chaos.Middleware())
+	}
+
+	c.handler = c.buildHandler()
+
 	return c
 }
 
 // doRequest performs the HTTP request
 func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}, opts ...RequestOption) (*http.Response, error) {
-	var reqBody bytes.Buffer
-	if body != nil {
-		jsonBody, err := json.Marshal(body)
-		if err != nil {
+	payload, err := marshalBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, opts = c.startCall(ctx, method, opts)
+
+	// Applying the options sets call state like the priority, needed before the first attempt
+	req, err := c.newRequest(ctx, method, c.baseURL+path, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.discovery != nil {
+		if err := c.discovery.ensure(ctx); err != nil {
 			return nil, err
 		}
-		reqBody.Write(jsonBody)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, &reqBody)
+	attempt := func(ctx context.Context, hedge bool) (*http.Response, error) {
+		if hedge && c.hedger.baseURL != "" {
+			return c.send(ctx, method, c.hedger.baseURL+path, payload, opts)
+		}
+		if c.balancer != nil {
+			return c.balancer.do(ctx, method, func(baseURL string) (*http.Response, error) {
+				return c.send(ctx, method, baseURL+path, payload, opts)
+			})
+		}
+		return c.send(ctx, method, c.baseURL+path, payload, opts)
+	}
+
+	call := func(ctx context.Context) (*http.Response, error) {
+		if c.hedger != nil && (method == http.MethodGet || method == http.MethodHead) {
+			return c.hedger.do(ctx, attempt)
+		}
+		return attempt(ctx, false)
+	}
+
+	if c.bulkhead != nil && c.bulkhead.global != nil {
+		limited := call
+		call = func(ctx context.Context) (*http.Response, error) {
+			return c.bulkhead.hold(ctx, limited)
+		}
+	}
+
+	if c.singleflight != nil && method == http.MethodGet && c.singleflight.shareable(req) {
+		return c.singleflight.do(ctx, c.singleflight.key(req), call)
+	}
+	return call(ctx)
+}
+
+// startCall attaches the state shared by every attempt of a call and the options derived from it
+func (c *Client) startCall(ctx context.Context, method string, opts []RequestOption) (context.Context, []RequestOption) {
+	ctx = withCallState(ctx)
+	return ctx, c.withIdempotencyKey(ctx, method, opts)
+}
+
+// send builds and performs a single attempt of a request
+func (c *Client) send(ctx context.Context, method, url string, payload []byte, opts []RequestOption) (*http.Response, error) {
+	req, err := c.newRequest(ctx, method, url, payload, opts)
 	if err != nil {
 		return nil, err
 	}
+	return c.handler(req)
+}
+
+// newRequest builds a request with the default headers and per-request options applied
+func (c *Client) newRequest(ctx context.Context, method, url string, payload []byte, opts []RequestOption) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	if c.decompress {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
 
 	// Set default headers
 	for k, v := range c.headers {
@@ -102,7 +284,15 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 		opt(req)
 	}
 
-	return c.client.Do(req)
+	return req, nil
+}
+
+// marshalBody encodes a request body as JSON, nil bodies stay empty
+func marshalBody(body interface{}) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+	return json.Marshal(body)
 }
 
 // Get performs a GET request
@@ -120,6 +310,11 @@ func (c *Client) Put(ctx context.Context, path string, body interface{}, opts ..
 	return c.doRequest(ctx, http.MethodPut, path, body, opts...)
 }
 
+// Patch performs a PATCH request
+func (c *Client) Patch(ctx context.Context, path string, body interface{}, opts ...RequestOption) (*http.Response, error) {
+	return c.doRequest(ctx, http.MethodPatch, path, body, opts...)
+}
+
 // Delete performs a DELETE request
 func (c *Client) Delete(ctx context.Context, path string, opts ...RequestOption) (*http.Response, error) {
 	return c.doRequest(ctx, http.MethodDelete, path, nil, opts...)
@@ -0,0 +1,208 @@
+package httpclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zlib"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content encodings for request bodies
+const (
+	EncodingGzip     = "gzip"
+	EncodingZstd     = "zstd"
+	EncodingIdentity = "identity" // No compression
+)
+
+// acceptEncoding lists the response encodings WithDecompression decodes, preferred first
+const acceptEncoding = "zstd, br, gzip, deflate"
+
+// CompressionConfig defines the configuration for request body compression
+type CompressionConfig struct {
+	Encoding string // EncodingGzip or EncodingZstd
+	MinSize  int    // Smaller bodies are sent uncompressed (default 1024)
+}
+
+// WithCompression compresses request bodies and sets their Content-Encoding
+func WithCompression(config CompressionConfig) Option {
+	return func(c *Client) {
+		if config.MinSize <= 0 {
+			config.MinSize = 1024
+		}
+		c.compression = config
+	}
+}
+
+// WithRequestCompression compresses the body of a single request regardless of its size,
+// EncodingIdentity sends it uncompressed. It requires a client configured with WithCompression.
+func WithRequestCompression(encoding string) RequestOption {
+	return func(req *http.Request) {
+		if state := callStateFrom(req.Context()); state != nil {
+			state.compression = encoding
+		}
+	}
+}
+
+// WithDecompression advertises zstd, br, gzip and deflate in Accept-Encoding and decodes
+// responses transparently. Requests setting their own Accept-Encoding get the raw body.
+func WithDecompression() Option {
+	return func(c *Client) {
+		c.decompress = true
+	}
+}
+
+// compressionTransport sits right above the base transport so every wrapper,
+// the logging middleware and curl output see plain request and response bodies
+type compressionTransport struct {
+	next       http.RoundTripper
+	config     CompressionConfig
+	decompress bool
+}
+
+func (t *compressionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, err := t.compressBody(req)
+	if err != nil {
+		return nil, err
+	}
+	decompress := t.decompress && req.Header.Get("Accept-Encoding") == acceptEncoding
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || !decompress {
+		return resp, err
+	}
+	decompressBody(resp)
+	return resp, nil
+}
+
+// compressBody returns a copy of req with a compressed body when compression applies
+func (t *compressionTransport) compressBody(req *http.Request) (*http.Request, error) {
+	encoding, minSize := t.config.Encoding, t.config.MinSize
+	if state := callStateFrom(req.Context()); state != nil && state.compression != "" {
+		encoding, minSize = state.compression, 0
+	}
+	if encoding == "" || encoding == EncodingIdentity || req.ContentLength == 0 || req.Header.Get("Content-Encoding") != "" {
+		return req, nil
+	}
+
+	payload, err := readRequestBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	if len(payload) < minSize {
+		return req, nil
+	}
+	compressed, err := compress(encoding, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(compressed))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	req.ContentLength = int64(len(compressed))
+	req.Header.Set("Content-Encoding", encoding)
+	return req, nil
+}
+
+// zstdEncoder is shared since EncodeAll is safe for concurrent use
+var zstdEncoder = sync.OnceValue(func() *zstd.Encoder {
+	encoder, _ := zstd.NewWriter(nil)
+	return encoder
+})
+
+func compress(encoding string, payload []byte) ([]byte, error) {
+	switch encoding {
+	case EncodingGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(payload); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case EncodingZstd:
+		return zstdEncoder().EncodeAll(payload, nil), nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+}
+
+// decompressBody replaces a response body in a supported encoding with its decoded content,
+// like http.Transport does for gzip
+func decompressBody(resp *http.Response) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	switch encoding {
+	case "zstd", "br", "gzip", "deflate":
+	default:
+		return
+	}
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return
+	}
+
+	resp.Body = &decodedBody{body: resp.Body, encoding: encoding}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// decodedBody creates its decoder on the first read so empty bodies don't fail early
+type decodedBody struct {
+	body     io.ReadCloser
+	encoding string
+	reader   io.Reader
+	err      error
+}
+
+func (b *decodedBody) Read(p []byte) (int, error) {
+	if b.reader == nil && b.err == nil {
+		b.reader, b.err = newDecoder(b.encoding, b.body)
+		if b.err != nil {
+			b.err = fmt.Errorf("failed to decode %s response body: %w", b.encoding, b.err)
+		}
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.reader.Read(p)
+}
+
+func (b *decodedBody) Close() error {
+	if closer, ok := b.reader.(io.Closer); ok {
+		closer.Close()
+	}
+	return b.body.Close()
+}
+
+func newDecoder(encoding string, r io.Reader) (io.Reader, error) {
+	switch encoding {
+	case "zstd":
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	case "br":
+		return brotli.NewReader(r), nil
+	case "gzip":
+		return gzip.NewReader(r)
+	default:
+		// HTTP deflate is zlib wrapped
+		return zlib.NewReader(r)
+	}
+}
@@ -0,0 +1,239 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zlib"
+	"github.com/klauspost/compress/zstd"
+)
+
+// receivedBody records the Content-Encoding and decoded body of a request
+type receivedBody struct {
+	encoding string
+	body     string
+}
+
+func newDecodingServer(t *testing.T) (*httptest.Server, chan receivedBody) {
+	received := make(chan receivedBody, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := r.Header.Get("Content-Encoding")
+		var reader io.Reader = r.Body
+		switch encoding {
+		case EncodingGzip:
+			reader, _ = gzip.NewReader(r.Body)
+		case EncodingZstd:
+			reader, _ = zstd.NewReader(r.Body)
+		}
+		body, err := io.ReadAll(reader)
+		if err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		received <- receivedBody{encoding: encoding, body: string(body)}
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func TestWithCompression(t *testing.T) {
+	large := map[string]string{"data": strings.Repeat("x", 2000)}
+	small := map[string]string{"data": "x"}
+
+	tests := []struct {
+		name     string
+		config   CompressionConfig
+		body     interface{}
+		opts     []RequestOption
+		expected string
+	}{
+		{name: "gzip", config: CompressionConfig{Encoding: EncodingGzip}, body: large, expected: EncodingGzip},
+		{name: "zstd", config: CompressionConfig{Encoding: EncodingZstd}, body: large, expected: EncodingZstd},
+		{name: "below min size", config: CompressionConfig{Encoding: EncodingGzip}, body: small},
+		{name: "request override", config: CompressionConfig{Encoding: EncodingGzip}, body: small, opts: []RequestOption{WithRequestCompression(EncodingZstd)}, expected: EncodingZstd},
+		{name: "request identity", config: CompressionConfig{Encoding: EncodingGzip}, body: large, opts: []RequestOption{WithRequestCompression(EncodingIdentity)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, received := newDecodingServer(t)
+			client := NewClient(server.URL, WithCompression(tt.config))
+
+			resp, err := client.Post(context.Background(), "/", tt.body, tt.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			got := <-received
+			if got.encoding != tt.expected {
+				t.Errorf("Expected Content-Encoding %q, got %q", tt.expected, got.encoding)
+			}
+			expected, _ := json.Marshal(tt.body)
+			if got.body != string(expected) {
+				t.Errorf("Expected body %.40s..., got %.40s...", expected, got.body)
+			}
+		})
+	}
+}
+
+func TestWithCompression_Unsupported(t *testing.T) {
+	client := NewClient("http://example.com", WithCompression(CompressionConfig{Encoding: "lz4", MinSize: 1}))
+
+	_, err := client.Post(context.Background(), "/", map[string]string{"data": "x"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported content encoding") {
+		t.Errorf("Expected an unsupported encoding error, got %v", err)
+	}
+}
+
+// encodeResponse compresses body with a response content encoding
+func encodeResponse(t *testing.T, encoding string, body []byte) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "zstd":
+		w, _ = zstd.NewWriter(&buf)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	}
+	if _, err := w.Write(body); err != nil {
+		t.Fatalf("Failed to encode response: %v", err)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func newEncodingServer(t *testing.T, encoding string, body []byte) *httptest.Server {
+	encoded := encodeResponse(t, encoding, body)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Accept-Encoding", r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Encoding", encoding)
+		w.Write(encoded)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWithDecompression(t *testing.T) {
+	body := []byte(`{"value":"` + strings.Repeat("decompressed", 100) + `"}`)
+
+	for _, encoding := range []string{"zstd", "br", "gzip", "deflate"} {
+		t.Run(encoding, func(t *testing.T) {
+			client := NewClient(newEncodingServer(t, encoding, body).URL, WithDecompression())
+
+			resp, err := client.Get(context.Background(), "/")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if got := resp.Header.Get("X-Accept-Encoding"); got != acceptEncoding {
+				t.Errorf("Expected Accept-Encoding %q, got %q", acceptEncoding, got)
+			}
+			if got := resp.Header.Get("Content-Encoding"); got != "" {
+				t.Errorf("Expected Content-Encoding to be removed, got %q", got)
+			}
+			got, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !bytes.Equal(got, body) {
+				t.Errorf("Expected the decoded body, got %.40s...", got)
+			}
+		})
+	}
+}
+
+func TestWithDecompression_ExplicitAcceptEncoding(t *testing.T) {
+	body := []byte(`{"value":"raw"}`)
+	client := NewClient(newEncodingServer(t, "br", body).URL, WithDecompression())
+
+	resp, err := client.Get(context.Background(), "/", WithRequestHeader("Accept-Encoding", "br"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.Header.Get("Content-Encoding") != "br" {
+		t.Errorf("Expected the raw br response, got Content-Encoding %q", resp.Header.Get("Content-Encoding"))
+	}
+	got, _ := io.ReadAll(resp.Body)
+	if !bytes.Equal(got, encodeResponse(t, "br", body)) {
+		t.Error("Expected the body to be left encoded")
+	}
+}
+
+func TestWithDecompression_MaxResponseBytes(t *testing.T) {
+	// A small compressed body that expands past the limit
+	server := newEncodingServer(t, "gzip", make([]byte, 100000))
+	client := NewClient(server.URL, WithDecompression(), WithMaxResponseBytes(1000))
+
+	resp, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.ReadAll(resp.Body); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("Expected the limit to apply to the decoded body, got %v", err)
+	}
+}
+
+func TestCompression_ObserversSeePlainBodies(t *testing.T) {
+	server, received := newDecodingServer(t)
+	var dump strings.Builder
+	var curl string
+	client := NewClient(server.URL,
+		WithCompression(CompressionConfig{Encoding: EncodingGzip, MinSize: 1}),
+		WithDebug(&dump),
+		WithCurlHook(func(cmd string) { curl = cmd }),
+	)
+
+	resp, err := client.Post(context.Background(), "/", map[string]string{"a": "b"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if got := <-received; got.encoding != EncodingGzip {
+		t.Errorf("Expected the body to be sent gzipped, got %q", got.encoding)
+	}
+	if !strings.Contains(dump.String(), `{"a":"b"}`) {
+		t.Errorf("Expected the debug dump to show the plain body, got %s", dump.String())
+	}
+	if strings.Contains(curl, "Content-Encoding") || !strings.Contains(curl, `{"a":"b"}`) {
+		t.Errorf("Expected a curl command with the plain body and no Content-Encoding, got %s", curl)
+	}
+
+	cmd, err := client.CurlCommand(context.Background(), http.MethodPost, "/", map[string]string{"a": "b"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(cmd, "Content-Encoding") {
+		t.Errorf("Expected CurlCommand without Content-Encoding, got %s", cmd)
+	}
+}
+
+func TestDecompression_ObserversSeeDecodedBodies(t *testing.T) {
+	body := []byte(`{"value":"decoded"}`)
+	var dump strings.Builder
+	client := NewClient(newEncodingServer(t, "br", body).URL, WithDecompression(), WithDebug(&dump))
+
+	readBody(t)(client.Get(context.Background(), "/"))
+
+	if !strings.Contains(dump.String(), string(body)) || strings.Contains(dump.String(), "binary body") {
+		t.Errorf("Expected the debug dump to show the decoded body, got %s", dump.String())
+	}
+}
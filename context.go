@@ -0,0 +1,38 @@
+package httpclient
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// callKey carries the state of one logical call through all of its attempts
+type callKey struct{}
+
+// callState is shared by every attempt made for one call to doRequest
+type callState struct {
+	attempts       int32
+	route          string
+	idempotencyKey string
+	priority       Priority
+
+	maxResponseBytes int64  // 0 uses the client limit, negative is unlimited
+	compression      string // Empty uses the client compression config
+}
+
+func withCallState(ctx context.Context) context.Context {
+	return context.WithValue(ctx, callKey{}, &callState{})
+}
+
+func callStateFrom(ctx context.Context) *callState {
+	state, _ := ctx.Value(callKey{}).(*callState)
+	return state
+}
+
+// nextAttempt returns how many attempts of the call preceded this one
+func nextAttempt(ctx context.Context) int {
+	state := callStateFrom(ctx)
+	if state == nil {
+		return 0
+	}
+	return int(atomic.AddInt32(&state.attempts, 1)) - 1
+}
@@ -0,0 +1,124 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// CurlConfig defines how requests are rendered as curl commands
+type CurlConfig struct {
+	Hook          func(cmd string) // Called with the curl command of every request sent, including redirects
+	Redact        bool             // Mask sensitive headers and fields (Authorization and cookies are always included)
+	RedactHeaders []string         // Additional header names to mask
+	RedactFields  []string         // JSON body field names to mask
+}
+
+// WithCurlConfig configures curl rendering for CurlCommand and the curl hook
+func WithCurlConfig(config CurlConfig) Option {
+	return func(c *Client) {
+		c.curlConfig = config
+	}
+}
+
+// WithCurlHook calls hook with every request sent, rendered as a curl command
+func WithCurlHook(hook func(cmd string)) Option {
+	return func(c *Client) {
+		c.curlConfig.Hook = hook
+	}
+}
+
+// CurlCommand renders the request doRequest would send as a curl command, including
+// default headers and cookies from the jar, without sending it.
+// Load balanced clients use the base URL passed to NewClient.
+func (c *Client) CurlCommand(ctx context.Context, method, path string, body interface{}, opts ...RequestOption) (string, error) {
+	payload, err := marshalBody(body)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, opts = c.startCall(ctx, method, opts)
+	req, err := c.newRequest(ctx, method, c.baseURL+path, payload, opts)
+	if err != nil {
+		return "", err
+	}
+	if c.client.Jar != nil {
+		for _, cookie := range c.client.Jar.Cookies(req.URL) {
+			req.AddCookie(cookie)
+		}
+	}
+
+	return renderCurl(req, payload, c.curlRedactor()), nil
+}
+
+// curlRedactor returns nil when masking is disabled
+func (c *Client) curlRedactor() *redactor {
+	if !c.curlConfig.Redact {
+		return nil
+	}
+	return newRedactor(c.curlConfig.RedactHeaders, c.curlConfig.RedactFields)
+}
+
+// curlTransport reports every request reaching the transport, after the jar added its cookies
+type curlTransport struct {
+	next   http.RoundTripper
+	hook   func(string)
+	redact *redactor
+}
+
+func (t *curlTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	t.hook(renderCurl(req, body, t.redact))
+	return t.next.RoundTrip(req)
+}
+
+// renderCurl formats req as a shell-quoted curl command with sorted headers
+func renderCurl(req *http.Request, body []byte, redact *redactor) string {
+	header := req.Header
+	if redact != nil {
+		header = redact.header(header)
+		body = redact.body(body)
+	}
+
+	parts := []string{"curl"}
+	if req.Method != http.MethodGet {
+		parts = append(parts, "-X", req.Method)
+	}
+	parts = append(parts, shellQuote(req.URL.String()))
+
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			parts = append(parts, "-H", shellQuote(name+": "+value))
+		}
+	}
+
+	if len(body) > 0 {
+		parts = append(parts, "--data-binary", shellQuote(string(body)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuote single-quotes s for POSIX shells, using ANSI-C quoting for binary data
+func shellQuote(s string) string {
+	if !utf8.ValidString(s) {
+		var b strings.Builder
+		b.WriteString("$'")
+		for i := 0; i < len(s); i++ {
+			fmt.Fprintf(&b, "\\x%02x", s[i])
+		}
+		b.WriteString("'")
+		return b.String()
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,115 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCurlCommand(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithAuth(), WithHeader("X-Client", "test"))
+	resp, err := client.Post(context.Background(), "/login", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	cmd, err := client.CurlCommand(context.Background(), http.MethodPost, "/notes",
+		map[string]string{"text": "it's here"},
+		WithRequestHeader("Authorization", "Bearer tok"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "curl -X POST '" + server.URL + "/notes'" +
+		" -H 'Authorization: Bearer tok'" +
+		" -H 'Content-Type: application/json'" +
+		" -H 'Cookie: session=abc'" +
+		" -H 'X-Client: test'" +
+		` --data-binary '{"text":"it'\''s here"}'`
+	if cmd != expected {
+		t.Errorf("Expected %s, got %s", expected, cmd)
+	}
+}
+
+func TestCurlCommand_Redact(t *testing.T) {
+	client := NewClient("https://api.example.com",
+		WithHeader("X-Api-Key", "key-123"),
+		WithCurlConfig(CurlConfig{Redact: true, RedactHeaders: []string{"X-Api-Key"}, RedactFields: []string{"password"}}),
+	)
+
+	cmd, err := client.CurlCommand(context.Background(), http.MethodPost, "/login",
+		map[string]string{"user": "alice", "password": "hunter2"},
+		WithRequestHeader("Authorization", "Bearer tok"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, secret := range []string{"key-123", "hunter2", "Bearer tok"} {
+		if strings.Contains(cmd, secret) {
+			t.Errorf("Expected %q to be masked in %s", secret, cmd)
+		}
+	}
+	if !strings.Contains(cmd, `"user":"alice"`) || !strings.Contains(cmd, redactedValue) {
+		t.Errorf("Expected only secrets to be masked, got %s", cmd)
+	}
+}
+
+func TestCurlCommand_IdempotencyKey(t *testing.T) {
+	client := NewClient("https://api.example.com", WithIdempotencyKeys(func() string { return "key-1" }))
+
+	cmd, err := client.CurlCommand(context.Background(), http.MethodPost, "/orders", map[string]int{"qty": 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(cmd, "-H 'Idempotency-Key: key-1'") {
+		t.Errorf("Expected the Idempotency-Key header doRequest sends, got %s", cmd)
+	}
+}
+
+func TestWithCurlHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var cmds []string
+	client := NewClient(server.URL, WithCurlHook(func(cmd string) {
+		mu.Lock()
+		cmds = append(cmds, cmd)
+		mu.Unlock()
+	}))
+
+	resp, err := client.Get(context.Background(), "/old")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(cmds) != 2 {
+		t.Fatalf("Expected a command per request including the redirect, got %v", cmds)
+	}
+	if cmds[0] != "curl '"+server.URL+"/old' -H 'Content-Type: application/json'" {
+		t.Errorf("Unexpected command %s", cmds[0])
+	}
+	if !strings.HasPrefix(cmds[1], "curl '"+server.URL+"/new'") {
+		t.Errorf("Expected the redirect to be reported, got %s", cmds[1])
+	}
+}
+
+func TestShellQuote_Binary(t *testing.T) {
+	if got := shellQuote("\xff\x00"); got != `$'\xff\x00'` {
+		t.Errorf("Expected ANSI-C quoting, got %s", got)
+	}
+}
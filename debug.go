@@ -0,0 +1,140 @@
+package httpclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// DebugConfig defines how exchanges are dumped by WithDebug
+type DebugConfig struct {
+	MaxBodyBytes  int      // Maximum body bytes dumped (default 4096, negative disables bodies)
+	RedactHeaders []string // Additional header names to mask (Authorization and cookies are always masked)
+	RedactFields  []string // JSON body field names to mask
+}
+
+// WithDebug writes a wire-format dump of every request and response to w
+func WithDebug(w io.Writer) Option {
+	return func(c *Client) {
+		c.transportWrappers = append(c.transportWrappers, func(next http.RoundTripper) http.RoundTripper {
+			return c.newDebugTransport(next, w)
+		})
+	}
+}
+
+// WithDebugConfig configures body truncation and redaction for WithDebug
+func WithDebugConfig(config DebugConfig) Option {
+	return func(c *Client) {
+		c.debugConfig = config
+	}
+}
+
+// debugTransport dumps exchanges as they reach the wire, after the jar added its cookies
+type debugTransport struct {
+	next     http.RoundTripper
+	maxBytes int
+	redact   *redactor
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// newDebugTransport reads the config when NewClient finalizes, so option order doesn't matter
+func (c *Client) newDebugTransport(next http.RoundTripper, w io.Writer) *debugTransport {
+	maxBytes := c.debugConfig.MaxBodyBytes
+	if maxBytes == 0 {
+		maxBytes = 4096
+	}
+	return &debugTransport{
+		next:     next,
+		maxBytes: maxBytes,
+		redact:   newRedactor(c.debugConfig.RedactHeaders, c.debugConfig.RedactFields),
+		w:        w,
+	}
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var dump bytes.Buffer
+	dump.WriteString(">>> request\n")
+	masked := *req
+	masked.Header = t.redact.header(req.Header)
+	// DumpRequestOut adds the headers the transport writes, like Content-Length and User-Agent
+	head, err := httputil.DumpRequestOut(&masked, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump request: %w", err)
+	}
+	dump.Write(head)
+	if t.maxBytes > 0 {
+		body, truncated := requestBody(req, t.maxBytes)
+		t.writeBody(&dump, body, truncated)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		fmt.Fprintf(&dump, "!!! error after %v: %v\n\n", time.Since(start), err)
+		t.write(dump.Bytes())
+		return nil, err
+	}
+
+	fmt.Fprintf(&dump, "<<< response after %v\n", time.Since(start))
+	maskedResp := *resp
+	maskedResp.Header = t.redact.header(resp.Header)
+	head, err = httputil.DumpResponse(&maskedResp, false)
+	if err == nil {
+		dump.Write(head)
+	}
+	if t.maxBytes > 0 {
+		body, truncated, replacement, _ := peekBody(resp.Body, t.maxBytes)
+		resp.Body = replacement
+		t.writeBody(&dump, body, truncated)
+	}
+	t.write(dump.Bytes())
+
+	return resp, nil
+}
+
+// writeBody appends a redacted body, replacing binary content with a placeholder
+func (t *debugTransport) writeBody(dump *bytes.Buffer, body []byte, truncated bool) {
+	if len(body) == 0 {
+		return
+	}
+	size := fmt.Sprint(len(body))
+	if truncated {
+		size += "+"
+	}
+	if isBinary(body, truncated) {
+		fmt.Fprintf(dump, "[binary body, %s bytes]\n\n", size)
+		return
+	}
+	dump.Write(t.redact.body(body))
+	if truncated {
+		dump.WriteString("...(truncated)")
+	}
+	dump.WriteString("\n\n")
+}
+
+// write emits one exchange at a time so concurrent dumps don't interleave
+func (t *debugTransport) write(p []byte) {
+	t.mu.Lock()
+	t.w.Write(p)
+	t.mu.Unlock()
+}
+
+// isBinary reports whether body isn't printable text, ignoring a rune cut off by truncation
+func isBinary(body []byte, truncated bool) bool {
+	if bytes.IndexByte(body, 0) >= 0 {
+		return true
+	}
+	if truncated {
+		for i := 0; i < utf8.UTFMax-1 && len(body) > 0 && !utf8.Valid(body); i++ {
+			body = body[:len(body)-1]
+		}
+	}
+	return !utf8.Valid(body)
+}
@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithDebug(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Trace", "t-1")
+		w.Write([]byte(`{"token":"tok-123","ok":true}`))
+	}))
+	defer server.Close()
+
+	out := &syncBuffer{}
+	client := NewClient(server.URL,
+		WithDebug(out),
+		WithDebugConfig(DebugConfig{RedactFields: []string{"password", "token"}}),
+	)
+
+	resp, err := client.Post(context.Background(), "/login",
+		map[string]string{"user": "alice", "password": "hunter2"},
+		WithRequestHeader("Authorization", "Bearer abc"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != `{"token":"tok-123","ok":true}` {
+		t.Errorf("Expected the body to remain readable, got %q", body)
+	}
+
+	dump := out.String()
+	for _, expected := range []string{
+		">>> request\n",
+		"POST /login HTTP/1.1\r\n",
+		"Authorization: " + redactedValue + "\r\n",
+		"Content-Length: ",
+		"User-Agent: Go-http-client/1.1\r\n",
+		`"user":"alice"`,
+		"<<< response after ",
+		"HTTP/1.1 200 OK\r\n",
+		"X-Trace: t-1\r\n",
+		`"ok":true`,
+	} {
+		if !strings.Contains(dump, expected) {
+			t.Errorf("Expected %q in dump %s", expected, dump)
+		}
+	}
+	for _, secret := range []string{"Bearer abc", "hunter2", "tok-123"} {
+		if strings.Contains(dump, secret) {
+			t.Errorf("Expected %q to be redacted from %s", secret, dump)
+		}
+	}
+}
+
+func TestWithDebug_TruncationAndBinary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/binary" {
+			w.Write([]byte{0x89, 'P', 'N', 'G', 0x00, 0x01})
+			return
+		}
+		w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer server.Close()
+
+	out := &syncBuffer{}
+	client := NewClient(server.URL, WithDebugConfig(DebugConfig{MaxBodyBytes: 10}), WithDebug(out))
+
+	for _, path := range []string{"/text", "/binary"} {
+		resp, err := client.Get(context.Background(), path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	dump := out.String()
+	if !strings.Contains(dump, "xxxxxxxxxx...(truncated)") || strings.Contains(dump, strings.Repeat("x", 11)) {
+		t.Errorf("Expected the body to be truncated to 10 bytes, got %s", dump)
+	}
+	if !strings.Contains(dump, "[binary body, 6 bytes]") {
+		t.Errorf("Expected binary body placeholder, got %s", dump)
+	}
+}
+
+func TestWithDebug_TransportError(t *testing.T) {
+	out := &syncBuffer{}
+	client := NewClient(deadURL(), WithDebug(out))
+
+	if _, err := client.Get(context.Background(), "/"); err == nil {
+		t.Fatal("Expected transport error, got none")
+	}
+	if !strings.Contains(out.String(), "!!! error after ") {
+		t.Errorf("Expected the error to be dumped, got %s", out.String())
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		body      string
+		truncated bool
+		expected  bool
+	}{
+		{"hello", false, false},
+		{"héllo", false, false},
+		{"h\xc3", true, false},
+		{"h\xc3", false, true},
+		{"a\x00b", false, true},
+	}
+	for _, tt := range tests {
+		if got := isBinary([]byte(tt.body), tt.truncated); got != tt.expected {
+			t.Errorf("isBinary(%q, %v): expected %v, got %v", tt.body, tt.truncated, tt.expected, got)
+		}
+	}
+}
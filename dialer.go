@@ -0,0 +1,31 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+)
+
+// DialFunc establishes the network connection for a request
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// WithDialer replaces how the transport establishes connections.
+// It has no effect when a custom transport is set with WithTransport.
+func WithDialer(dial DialFunc) Option {
+	return func(c *Client) {
+		c.dial = dial
+		c.unixSocket = ""
+	}
+}
+
+// WithUnixSocket sends every request over the Unix domain socket at path.
+// The host of the base URL is then only used for the Host header, e.g. "http://unix",
+// and is never resolved, not even by WithDNSCache.
+// It has no effect when a custom transport is set with WithTransport.
+func WithUnixSocket(path string) Option {
+	return func(c *Client) {
+		c.unixSocket = path
+		c.dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return c.dialer.DialContext(ctx, "unix", path)
+		}
+	}
+}
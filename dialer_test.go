@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWithUnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "api.sock")
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("Unix sockets not supported: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(TestData{Message: r.Method + " " + r.URL.Path, Status: r.Host})
+	}))
+	server.Listener = ln
+	server.Start()
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "plain"},
+		// "unix" must not be resolved as a host name
+		{name: "with dns cache", opts: []Option{WithDNSCache(DNSCacheConfig{})}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient("http://unix", append(tt.opts, WithUnixSocket(socketPath))...)
+
+			resp, err := client.Post(context.Background(), "/containers/create", map[string]string{"image": "alpine"})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			var data TestData
+			if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if data.Message != "POST /containers/create" {
+				t.Errorf("Unexpected request line %q", data.Message)
+			}
+			if data.Status != "unix" {
+				t.Errorf("Expected Host header unix, got %q", data.Status)
+			}
+		})
+	}
+}
+
+func TestWithDialer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	var dials int32
+	var dialedAddr atomic.Value
+	dialer := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		atomic.AddInt32(&dials, 1)
+		dialedAddr.Store(addr)
+		var d net.Dialer
+		return d.DialContext(ctx, network, server.Listener.Addr().String())
+	}
+
+	client := NewClient("http://sidecar.internal:8080", WithDialer(dialer))
+	resp, err := client.Get(context.Background(), "/health")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected status %d, got %d", http.StatusNoContent, resp.StatusCode)
+	}
+	if atomic.LoadInt32(&dials) != 1 {
+		t.Errorf("Expected 1 dial, got %d", dials)
+	}
+	if addr, _ := dialedAddr.Load().(string); addr != "sidecar.internal:8080" {
+		t.Errorf("Expected dial to sidecar.internal:8080, got %q", addr)
+	}
+}
@@ -0,0 +1,176 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SRVResolver looks up SRV records, net.DefaultResolver satisfies it
+type SRVResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// DiscoveryConfig defines how SRV-based base URLs are resolved
+type DiscoveryConfig struct {
+	Resolver        SRVResolver   // Custom resolver, defaults to net.DefaultResolver
+	RefreshInterval time.Duration // How long records are used before being re-resolved (default 30s)
+	RetryAfter      time.Duration // How long to wait before retrying a failed lookup (default 5s)
+	MaxFailures     int           // Consecutive failures before a target is ejected (default 3)
+	EjectFor        time.Duration // How long an ejected target is skipped (default 30s)
+}
+
+// WithDiscovery configures how a base URL like "srv+http://_api._tcp.service"
+// is resolved. Such base URLs use net.DefaultResolver and default settings otherwise.
+// Records are refreshed in the background by the first request finding them older than
+// RefreshInterval, an idle client doesn't resolve. Concurrent requests share one lookup.
+func WithDiscovery(config DiscoveryConfig) Option {
+	return func(c *Client) {
+		c.discoveryConfig = config
+	}
+}
+
+// srvDiscovery keeps the balancer's endpoints in sync with SRV records.
+// Targets are picked from the lowest priority group by weight, falling back
+// to higher priorities as targets fail. Weight 0 targets only serve requests
+// once no weighted target of their priority is left, following RFC 2782.
+type srvDiscovery struct {
+	scheme     string
+	name       string
+	path       string
+	resolver   SRVResolver
+	interval   time.Duration
+	retryAfter time.Duration
+	balancer   *balancer
+	err        error // set when the base URL is malformed
+
+	mu          sync.Mutex
+	resolved    bool
+	lookupErr   error         // error of the last lookup while none has succeeded yet
+	nextRefresh time.Time     // when the records are looked up again
+	pending     chan struct{} // closed when the lookup in flight finishes
+}
+
+// newSRVDiscovery parses a base URL of the form srv+<scheme>://<name>[/path]
+func newSRVDiscovery(baseURL string, config DiscoveryConfig) *srvDiscovery {
+	rest := strings.TrimPrefix(baseURL, "srv+")
+	scheme, name, ok := strings.Cut(rest, "://")
+	if !ok || name == "" {
+		return &srvDiscovery{err: fmt.Errorf("invalid discovery URL: %s", baseURL)}
+	}
+
+	path := ""
+	if i := strings.Index(name, "/"); i >= 0 {
+		name, path = name[:i], strings.TrimSuffix(name[i:], "/")
+	}
+
+	if config.Resolver == nil {
+		config.Resolver = net.DefaultResolver
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = 30 * time.Second
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = 5 * time.Second
+	}
+
+	return &srvDiscovery{
+		scheme:     scheme,
+		name:       name,
+		path:       path,
+		resolver:   config.Resolver,
+		interval:   config.RefreshInterval,
+		retryAfter: config.RetryAfter,
+		balancer: newBalancer(LoadBalancerConfig{
+			Strategy:    srvPriorityWeighted,
+			MaxFailures: config.MaxFailures,
+			EjectFor:    config.EjectFor,
+		}),
+	}
+}
+
+// ensure resolves the records on first use and refreshes them in the background once stale.
+// Only the first use waits for the lookup, later ones keep serving the previous targets.
+func (d *srvDiscovery) ensure(ctx context.Context) error {
+	if d.err != nil {
+		return d.err
+	}
+
+	d.mu.Lock()
+	if d.pending == nil && !time.Now().Before(d.nextRefresh) {
+		d.pending = make(chan struct{})
+		go d.refresh(d.pending)
+	}
+	resolved, pending, err := d.resolved, d.pending, d.lookupErr
+	d.mu.Unlock()
+
+	if resolved {
+		return nil
+	}
+	// Still backing off after a failed lookup
+	if pending == nil {
+		return err
+	}
+
+	select {
+	case <-pending:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.resolved {
+		return nil
+	}
+	return d.lookupErr
+}
+
+// refresh looks up the records and closes done. A failed lookup keeps the previous
+// targets and is retried after retryAfter so an outage doesn't trigger a lookup per request.
+func (d *srvDiscovery) refresh(done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	endpoints, err := d.lookup(ctx)
+
+	d.mu.Lock()
+	if err != nil {
+		d.lookupErr = err
+		d.nextRefresh = time.Now().Add(d.retryAfter)
+	} else {
+		d.balancer.setEndpoints(endpoints)
+		d.resolved = true
+		d.lookupErr = nil
+		d.nextRefresh = time.Now().Add(d.interval)
+	}
+	d.pending = nil
+	d.mu.Unlock()
+	close(done)
+}
+
+// lookup resolves the SRV records into balancer endpoints
+func (d *srvDiscovery) lookup(ctx context.Context) ([]Endpoint, error) {
+	_, records, err := d.resolver.LookupSRV(ctx, "", "", d.name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", d.name, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no SRV records for %s", d.name)
+	}
+
+	endpoints := make([]Endpoint, 0, len(records))
+	for _, srv := range records {
+		host := strings.TrimSuffix(srv.Target, ".")
+		endpoints = append(endpoints, Endpoint{
+			URL:      d.scheme + "://" + net.JoinHostPort(host, strconv.Itoa(int(srv.Port))) + d.path,
+			Weight:   int(srv.Weight),
+			Priority: int(srv.Priority),
+		})
+	}
+	return endpoints, nil
+}
@@ -0,0 +1,247 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubSRVResolver answers SRV lookups from a mutable record set
+type stubSRVResolver struct {
+	mu      sync.Mutex
+	records []*net.SRV
+	err     error
+	delay   time.Duration
+	lookups int32
+}
+
+func (r *stubSRVResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	atomic.AddInt32(&r.lookups, 1)
+	time.Sleep(r.delay)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return name, r.records, r.err
+}
+
+func (r *stubSRVResolver) set(records ...*net.SRV) {
+	r.mu.Lock()
+	r.records = records
+	r.mu.Unlock()
+}
+
+func srvFor(t *testing.T, rawURL string, priority, weight uint16) *net.SRV {
+	t.Helper()
+	u, _ := url.Parse(rawURL)
+	port, _ := strconv.Atoi(u.Port())
+	return &net.SRV{Target: u.Hostname() + ".", Port: uint16(port), Priority: priority, Weight: weight}
+}
+
+func TestDiscovery_Priority(t *testing.T) {
+	primary, primaryHits := newNamedServer(t, "primary", http.StatusOK)
+	backup, backupHits := newNamedServer(t, "backup", http.StatusOK)
+
+	resolver := &stubSRVResolver{}
+	resolver.set(srvFor(t, backup.URL, 20, 1), srvFor(t, primary.URL, 10, 1))
+
+	client := NewClient("srv+http://_api._tcp.service.test", WithDiscovery(DiscoveryConfig{Resolver: resolver}))
+
+	for i := 0; i < 5; i++ {
+		getBody(t, client, "/")
+	}
+	if *primaryHits != 5 || *backupHits != 0 {
+		t.Errorf("Expected the lowest priority target only, got primary=%d backup=%d", *primaryHits, *backupHits)
+	}
+}
+
+func TestDiscovery_FallbackToNextPriority(t *testing.T) {
+	backup, backupHits := newNamedServer(t, "backup", http.StatusOK)
+
+	resolver := &stubSRVResolver{}
+	resolver.set(srvFor(t, deadURL(), 10, 1), srvFor(t, backup.URL, 20, 1))
+
+	client := NewClient("srv+http://_api._tcp.service.test", WithDiscovery(DiscoveryConfig{Resolver: resolver}))
+
+	if body, _ := getBody(t, client, "/"); body != "backup" {
+		t.Errorf("Expected the backup target, got %q", body)
+	}
+	if *backupHits != 1 {
+		t.Errorf("Expected 1 backup hit, got %d", *backupHits)
+	}
+}
+
+func TestDiscovery_Weight(t *testing.T) {
+	b := newBalancer(LoadBalancerConfig{Strategy: srvPriorityWeighted})
+	b.setEndpoints([]Endpoint{
+		{URL: "http://heavy", Weight: 90},
+		{URL: "http://light", Weight: 10},
+	})
+
+	counts := make(map[string]int)
+	for i := 0; i < 2000; i++ {
+		e := b.pick(nil)
+		counts[e.url]++
+		b.release(e)
+	}
+
+	if counts["http://heavy"] < 1600 || counts["http://light"] < 100 {
+		t.Errorf("Expected roughly a 9:1 split, got %v", counts)
+	}
+}
+
+func TestDiscovery_ZeroWeight(t *testing.T) {
+	b := newBalancer(LoadBalancerConfig{Strategy: srvPriorityWeighted})
+	b.setEndpoints([]Endpoint{
+		{URL: "http://weighted", Weight: 1},
+		{URL: "http://zero-a", Weight: 0},
+		{URL: "http://zero-b", Weight: 0},
+	})
+
+	for i := 0; i < 100; i++ {
+		e := b.pick(nil)
+		if e.url != "http://weighted" {
+			t.Fatalf("Expected weight 0 targets to be skipped while a weighted one is left, got %s", e.url)
+		}
+		b.release(e)
+	}
+
+	// Once only weight 0 targets are left they share the load
+	counts := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		e := b.pick(map[*endpoint]bool{b.endpoints[0]: true})
+		counts[e.url]++
+		b.release(e)
+	}
+	if counts["http://zero-a"] == 0 || counts["http://zero-b"] == 0 {
+		t.Errorf("Expected both weight 0 targets to be used, got %v", counts)
+	}
+}
+
+func TestDiscovery_Refresh(t *testing.T) {
+	first, _ := newNamedServer(t, "first", http.StatusOK)
+	second, _ := newNamedServer(t, "second", http.StatusOK)
+
+	resolver := &stubSRVResolver{}
+	resolver.set(srvFor(t, first.URL, 0, 1))
+
+	client := NewClient("srv+http://_api._tcp.service.test", WithDiscovery(DiscoveryConfig{
+		Resolver:        resolver,
+		RefreshInterval: 50 * time.Millisecond,
+	}))
+
+	if body, _ := getBody(t, client, "/"); body != "first" {
+		t.Fatalf("Expected first target, got %q", body)
+	}
+
+	resolver.set(srvFor(t, second.URL, 0, 1))
+	time.Sleep(75 * time.Millisecond)
+
+	// The stale records trigger a background refresh
+	getBody(t, client, "/")
+	deadline := time.Now().Add(time.Second)
+	for {
+		if body, _ := getBody(t, client, "/"); body == "second" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected refreshed target to be used")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDiscovery_RefreshFailureBacksOff(t *testing.T) {
+	server, _ := newNamedServer(t, "ok", http.StatusOK)
+
+	resolver := &stubSRVResolver{}
+	resolver.set(srvFor(t, server.URL, 0, 1))
+
+	client := NewClient("srv+http://_api._tcp.service.test", WithDiscovery(DiscoveryConfig{
+		Resolver:        resolver,
+		RefreshInterval: 10 * time.Millisecond,
+		RetryAfter:      time.Minute,
+	}))
+	getBody(t, client, "/")
+
+	resolver.mu.Lock()
+	resolver.err = errors.New("lookup failed")
+	resolver.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+
+	for i := 0; i < 50; i++ {
+		if body, _ := getBody(t, client, "/"); body != "ok" {
+			t.Fatalf("Expected the previous target to keep serving, got %q", body)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if lookups := atomic.LoadInt32(&resolver.lookups); lookups != 2 {
+		t.Errorf("Expected 2 lookups while backing off, got %d", lookups)
+	}
+}
+
+func TestDiscovery_ConcurrentFirstUse(t *testing.T) {
+	server, _ := newNamedServer(t, "ok", http.StatusOK)
+
+	resolver := &stubSRVResolver{delay: 50 * time.Millisecond}
+	resolver.set(srvFor(t, server.URL, 0, 1))
+
+	client := NewClient("srv+http://_api._tcp.service.test", WithDiscovery(DiscoveryConfig{Resolver: resolver}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(context.Background(), "/")
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if lookups := atomic.LoadInt32(&resolver.lookups); lookups != 1 {
+		t.Errorf("Expected concurrent requests to share 1 lookup, got %d", lookups)
+	}
+}
+
+func TestDiscovery_Errors(t *testing.T) {
+	resolver := &stubSRVResolver{err: errors.New("lookup failed")}
+	client := NewClient("srv+http://_api._tcp.service.test", WithDiscovery(DiscoveryConfig{Resolver: resolver}))
+	if _, err := client.Get(context.Background(), "/"); err == nil {
+		t.Error("Expected resolver error, got none")
+	}
+
+	empty := NewClient("srv+http://_api._tcp.service.test", WithDiscovery(DiscoveryConfig{Resolver: &stubSRVResolver{}}))
+	if _, err := empty.Get(context.Background(), "/"); err == nil {
+		t.Error("Expected error for missing records, got none")
+	}
+
+	invalid := NewClient("srv+http//missing-separator")
+	if _, err := invalid.Get(context.Background(), "/"); err == nil {
+		t.Error("Expected error for malformed discovery URL, got none")
+	}
+}
+
+func TestDiscovery_BasePath(t *testing.T) {
+	d := newSRVDiscovery("srv+https://_api._tcp.service.test/v1/", DiscoveryConfig{
+		Resolver: &stubSRVResolver{records: []*net.SRV{{Target: "api-1.service.test.", Port: 8443}}},
+	})
+	if err := d.ensure(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	e := d.balancer.pick(nil)
+	if e.url != "https://api-1.service.test:8443/v1" {
+		t.Errorf("Unexpected target URL %q", e.url)
+	}
+}
@@ -0,0 +1,246 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LookupFunc resolves a host name to IP addresses.
+// A positive ttl overrides DNSCacheConfig.TTL for the returned addresses.
+// The default lookup through net.DefaultResolver can't see record TTLs and reports none.
+type LookupFunc func(ctx context.Context, host string) (addrs []string, ttl time.Duration, err error)
+
+// DNSCacheConfig defines the configuration for the in-process DNS cache
+type DNSCacheConfig struct {
+	TTL         time.Duration       // How long resolved addresses are cached when the lookup reports no TTL, always the case for the default lookup (default 1m)
+	NegativeTTL time.Duration       // How long failed lookups are cached (default 5s)
+	Hosts       map[string][]string // Static host overrides that are never resolved
+	Lookup      LookupFunc          // Custom lookup, required to honor record TTLs (defaults to net.DefaultResolver)
+}
+
+// WithDNSCache caches host name lookups made by the dialer.
+// Entries past half their TTL are refreshed in the background while the cached
+// addresses keep being served, and connections rotate across all returned addresses.
+// Concurrent dials missing the same host share a single lookup.
+// It has no effect when a custom transport is set with WithTransport.
+func WithDNSCache(config DNSCacheConfig) Option {
+	return func(c *Client) {
+		c.dnsCache = newDNSCache(config)
+	}
+}
+
+type dnsCache struct {
+	config  DNSCacheConfig
+	static  map[string]*dnsEntry
+	entries map[string]*dnsEntry
+	pending map[string]*dnsLookup
+	mu      sync.Mutex
+}
+
+// dnsLookup is a lookup in flight, shared by every dial missing the same host
+type dnsLookup struct {
+	done  chan struct{}
+	entry *dnsEntry
+}
+
+type dnsEntry struct {
+	addrs      []string
+	err        error
+	expires    time.Time
+	refreshAt  time.Time
+	refreshing int32
+	next       uint32
+}
+
+func newDNSCache(config DNSCacheConfig) *dnsCache {
+	if config.TTL <= 0 {
+		config.TTL = time.Minute
+	}
+	if config.NegativeTTL <= 0 {
+		config.NegativeTTL = 5 * time.Second
+	}
+	if config.Lookup == nil {
+		config.Lookup = func(ctx context.Context, host string) ([]string, time.Duration, error) {
+			addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+			return addrs, 0, err
+		}
+	}
+
+	static := make(map[string]*dnsEntry, len(config.Hosts))
+	for host, addrs := range config.Hosts {
+		static[host] = &dnsEntry{addrs: addrs}
+	}
+
+	return &dnsCache{
+		config:  config,
+		static:  static,
+		entries: make(map[string]*dnsEntry),
+		pending: make(map[string]*dnsLookup),
+	}
+}
+
+// wrap returns a dial function that resolves host names through the cache
+// and tries each address in round-robin order until one connects
+func (d *dnsCache) wrap(dial DialFunc) DialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dial(ctx, network, addr)
+		}
+
+		addrs, err := d.resolve(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		var firstErr error
+		for _, ip := range addrs {
+			conn, err := dial(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return nil, firstErr
+	}
+}
+
+// resolve returns the addresses for host, rotated for round-robin
+func (d *dnsCache) resolve(ctx context.Context, host string) ([]string, error) {
+	if entry, ok := d.static[host]; ok {
+		return entry.rotate(), nil
+	}
+
+	d.mu.Lock()
+	entry := d.entries[host]
+	d.mu.Unlock()
+
+	now := time.Now()
+	if entry == nil || now.After(entry.expires) {
+		var err error
+		if entry, err = d.await(ctx, host); err != nil {
+			return nil, err
+		}
+	} else if entry.err == nil && now.After(entry.refreshAt) && atomic.CompareAndSwapInt32(&entry.refreshing, 0, 1) {
+		go d.refresh(host)
+	}
+
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	return entry.rotate(), nil
+}
+
+// await returns the result of looking up host, joining a lookup already in flight.
+// The lookup outlives callers giving up, so one cancelled dial doesn't fail the others.
+func (d *dnsCache) await(ctx context.Context, host string) (*dnsEntry, error) {
+	d.mu.Lock()
+	call, ok := d.pending[host]
+	if !ok {
+		call = &dnsLookup{done: make(chan struct{})}
+		d.pending[host] = call
+		go func() {
+			lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+			defer cancel()
+			call.entry = d.lookup(lookupCtx, host)
+
+			d.mu.Lock()
+			delete(d.pending, host)
+			d.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.entry, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// lookup resolves host and stores the result, including failures
+func (d *dnsCache) lookup(ctx context.Context, host string) *dnsEntry {
+	addrs, ttl, err := d.config.Lookup(ctx, host)
+	if err == nil && len(addrs) == 0 {
+		err = &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	now := time.Now()
+	entry := &dnsEntry{addrs: addrs, err: err}
+	switch {
+	case err != nil:
+		// Don't cache failures caused by the caller giving up
+		if ctx.Err() != nil {
+			return entry
+		}
+		entry.expires = now.Add(d.config.NegativeTTL)
+	default:
+		if ttl <= 0 {
+			ttl = d.config.TTL
+		}
+		entry.expires = now.Add(ttl)
+		entry.refreshAt = now.Add(ttl / 2)
+	}
+
+	d.mu.Lock()
+	d.entries[host] = entry
+	d.mu.Unlock()
+
+	return entry
+}
+
+// refresh re-resolves host in the background. On failure the current addresses are kept
+// and the next refresh waits NegativeTTL so an outage doesn't trigger a lookup per dial.
+func (d *dnsCache) refresh(host string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	addrs, ttl, err := d.config.Lookup(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		d.mu.Lock()
+		if entry, exists := d.entries[host]; exists {
+			d.entries[host] = &dnsEntry{
+				addrs:     entry.addrs,
+				expires:   entry.expires,
+				refreshAt: time.Now().Add(d.config.NegativeTTL),
+				next:      atomic.LoadUint32(&entry.next),
+			}
+		}
+		d.mu.Unlock()
+		return
+	}
+
+	if ttl <= 0 {
+		ttl = d.config.TTL
+	}
+	now := time.Now()
+
+	d.mu.Lock()
+	d.entries[host] = &dnsEntry{
+		addrs:     addrs,
+		expires:   now.Add(ttl),
+		refreshAt: now.Add(ttl / 2),
+	}
+	d.mu.Unlock()
+}
+
+// rotate returns the addresses starting at the next round-robin position
+func (e *dnsEntry) rotate() []string {
+	if len(e.addrs) <= 1 {
+		return e.addrs
+	}
+	start := int(atomic.AddUint32(&e.next, 1)-1) % len(e.addrs)
+	rotated := make([]string, 0, len(e.addrs))
+	rotated = append(rotated, e.addrs[start:]...)
+	return append(rotated, e.addrs[:start]...)
+}
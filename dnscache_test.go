@@ -0,0 +1,235 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithDNSCache_StaticHosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	var lookups int32
+
+	client := NewClient("http://api.test:"+serverURL.Port(), WithDNSCache(DNSCacheConfig{
+		Hosts: map[string][]string{"api.test": {"127.0.0.1"}},
+		Lookup: func(ctx context.Context, host string) ([]string, time.Duration, error) {
+			atomic.AddInt32(&lookups, 1)
+			return nil, 0, errors.New("unexpected lookup")
+		},
+	}))
+
+	resp, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if lookups != 0 {
+		t.Errorf("Expected static host to skip lookups, got %d", lookups)
+	}
+}
+
+func TestDNSCache_TTL(t *testing.T) {
+	var lookups int32
+	cache := newDNSCache(DNSCacheConfig{
+		TTL: time.Hour,
+		Lookup: func(ctx context.Context, host string) ([]string, time.Duration, error) {
+			atomic.AddInt32(&lookups, 1)
+			return []string{"10.0.0.1"}, 50 * time.Millisecond, nil
+		},
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.resolve(context.Background(), "api.test"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if atomic.LoadInt32(&lookups) != 1 {
+		t.Errorf("Expected 1 lookup while cached, got %d", lookups)
+	}
+
+	// The TTL reported by the lookup takes precedence over the configured one
+	time.Sleep(75 * time.Millisecond)
+	if _, err := cache.resolve(context.Background(), "api.test"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if atomic.LoadInt32(&lookups) != 2 {
+		t.Errorf("Expected a new lookup after expiry, got %d lookups", lookups)
+	}
+}
+
+func TestDNSCache_NegativeCaching(t *testing.T) {
+	var lookups int32
+	cache := newDNSCache(DNSCacheConfig{
+		NegativeTTL: time.Minute,
+		Lookup: func(ctx context.Context, host string) ([]string, time.Duration, error) {
+			atomic.AddInt32(&lookups, 1)
+			return nil, 0, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		},
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.resolve(context.Background(), "missing.test"); err == nil {
+			t.Fatal("Expected lookup error, got none")
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("Expected failed lookup to be cached, got %d lookups", lookups)
+	}
+}
+
+func TestDNSCache_ConcurrentMisses(t *testing.T) {
+	var lookups int32
+	cache := newDNSCache(DNSCacheConfig{
+		Lookup: func(ctx context.Context, host string) ([]string, time.Duration, error) {
+			atomic.AddInt32(&lookups, 1)
+			time.Sleep(50 * time.Millisecond)
+			return []string{"10.0.0.1"}, 0, nil
+		},
+	})
+
+	// A caller giving up leaves the shared lookup running for the others
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cache.resolve(ctx, "api.test"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.resolve(context.Background(), "api.test"); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&lookups); n != 1 {
+		t.Errorf("Expected concurrent misses to share 1 lookup, got %d", n)
+	}
+}
+
+func TestDNSCache_BackgroundRefresh(t *testing.T) {
+	var lookups int32
+	refreshed := make(chan struct{})
+	var once sync.Once
+
+	cache := newDNSCache(DNSCacheConfig{
+		TTL: 100 * time.Millisecond,
+		Lookup: func(ctx context.Context, host string) ([]string, time.Duration, error) {
+			if atomic.AddInt32(&lookups, 1) == 1 {
+				return []string{"10.0.0.1"}, 0, nil
+			}
+			once.Do(func() { close(refreshed) })
+			return []string{"10.0.0.2"}, 0, nil
+		},
+	})
+
+	if _, err := cache.resolve(context.Background(), "api.test"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Past half the TTL the stale addresses are served while refreshing
+	time.Sleep(60 * time.Millisecond)
+	addrs, err := cache.resolve(context.Background(), "api.test")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if addrs[0] != "10.0.0.1" {
+		t.Errorf("Expected cached address during refresh, got %v", addrs)
+	}
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected background refresh")
+	}
+
+	// Give the refresh goroutine a moment to store its result
+	time.Sleep(10 * time.Millisecond)
+	addrs, _ = cache.resolve(context.Background(), "api.test")
+	if addrs[0] != "10.0.0.2" {
+		t.Errorf("Expected refreshed address, got %v", addrs)
+	}
+}
+
+func TestDNSCache_RefreshFailureBacksOff(t *testing.T) {
+	var lookups int32
+	cache := newDNSCache(DNSCacheConfig{
+		TTL:         100 * time.Millisecond,
+		NegativeTTL: time.Hour,
+		Lookup: func(ctx context.Context, host string) ([]string, time.Duration, error) {
+			if atomic.AddInt32(&lookups, 1) == 1 {
+				return []string{"10.0.0.1"}, 0, nil
+			}
+			return nil, 0, errors.New("dns outage")
+		},
+	})
+
+	if _, err := cache.resolve(context.Background(), "api.test"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Past half the TTL, every dial during the outage must not start another refresh
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		addrs, err := cache.resolve(context.Background(), "api.test")
+		if err != nil || addrs[0] != "10.0.0.1" {
+			t.Fatalf("Expected the cached address during the outage, got %v %v", addrs, err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	if n := atomic.LoadInt32(&lookups); n != 2 {
+		t.Errorf("Expected a single failed refresh, got %d lookups", n)
+	}
+}
+
+func TestDNSCache_RoundRobin(t *testing.T) {
+	cache := newDNSCache(DNSCacheConfig{
+		Hosts: map[string][]string{"api.test": {"10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+	})
+
+	var mu sync.Mutex
+	var dialed []string
+	dial := cache.wrap(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		mu.Lock()
+		dialed = append(dialed, addr)
+		mu.Unlock()
+		return nil, errors.New("refused")
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := dial(context.Background(), "tcp", "api.test:80"); err == nil {
+			t.Fatal("Expected dial error, got none")
+		}
+	}
+
+	expected := []string{
+		"10.0.0.1:80", "10.0.0.2:80", "10.0.0.3:80",
+		"10.0.0.2:80", "10.0.0.3:80", "10.0.0.1:80",
+	}
+	if len(dialed) != len(expected) {
+		t.Fatalf("Expected %d dials, got %v", len(expected), dialed)
+	}
+	for i := range expected {
+		if dialed[i] != expected[i] {
+			t.Errorf("Dial %d: expected %s, got %s", i, expected[i], dialed[i])
+		}
+	}
+}
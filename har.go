@@ -0,0 +1,446 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// HARConfig defines the configuration for recording traffic as an HTTP Archive
+type HARConfig struct {
+	Path          string   // File written by Flush
+	MaxBodyBytes  int      // Maximum recorded bytes per body (default 64KiB), negative disables body capture
+	RedactHeaders []string // Extra headers to mask, Authorization and cookies are always masked
+	RedactFields  []string // JSON field names whose values are masked in recorded bodies
+}
+
+// HARRecorder collects every exchange of the clients it's attached to in HAR 1.2 format
+type HARRecorder struct {
+	config HARConfig
+	redact *redactor
+
+	mu      sync.Mutex
+	entries []*harEntry
+}
+
+// NewHARRecorder creates a recorder to pass to WithHAR
+func NewHARRecorder(config HARConfig) *HARRecorder {
+	if config.MaxBodyBytes == 0 {
+		config.MaxBodyBytes = 64 << 10
+	}
+	return &HARRecorder{
+		config: config,
+		redact: newRedactor(config.RedactHeaders, config.RedactFields),
+	}
+}
+
+// WithHAR records every exchange, including cookies added from the jar, into rec
+func WithHAR(rec *HARRecorder) Option {
+	return func(c *Client) {
+		c.transportWrappers = append(c.transportWrappers, func(next http.RoundTripper) http.RoundTripper {
+			return &harTransport{next: next, recorder: rec}
+		})
+	}
+}
+
+// Flush writes all exchanges recorded so far to the configured path.
+// Recorded exchanges are kept, so every Flush rewrites the whole file, call Reset to drop them.
+func (r *HARRecorder) Flush() error {
+	if r.config.Path == "" {
+		return fmt.Errorf("no HAR path configured")
+	}
+
+	f, err := os.Create(r.config.Path)
+	if err != nil {
+		return fmt.Errorf("failed to create HAR file: %w", err)
+	}
+	if _, err := r.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Reset drops the exchanges recorded so far, bounding memory for long-running clients
+func (r *HARRecorder) Reset() {
+	r.mu.Lock()
+	r.entries = nil
+	r.mu.Unlock()
+}
+
+// WriteTo writes all exchanges recorded so far as a HAR document
+func (r *HARRecorder) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	entries := make([]harEntry, len(r.entries))
+	for i, entry := range r.entries {
+		entries[i] = *entry
+	}
+	r.mu.Unlock()
+
+	doc := harDocument{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "github.com/samhoque/httpclient", Version: "1.0"},
+		Entries: entries,
+	}}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal HAR: %w", err)
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// harTransport records exchanges passing through the wrapped transport
+type harTransport struct {
+	next     http.RoundTripper
+	recorder *HARRecorder
+}
+
+func (t *harTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := t.recorder
+	entry := &harEntry{
+		StartedDateTime: time.Now(),
+		Request:         r.request(req),
+		Cache:           struct{}{},
+	}
+
+	var phases harPhases
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), phases.trace()))
+
+	resp, err := t.next.RoundTrip(req)
+	phases.headersAt = time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+
+	if err != nil {
+		entry.Response = harResponse{Cookies: []harCookie{}, Headers: []harNameValue{}, HeadersSize: -1, BodySize: -1}
+		entry.Error = err.Error()
+		entry.Timings = phases.timings(phases.headersAt)
+		entry.Time = entry.Timings.total()
+		return nil, err
+	}
+
+	entry.Response = r.response(resp)
+	entry.Timings = phases.timings(phases.headersAt)
+	entry.Time = entry.Timings.total()
+
+	resp.Body = &harBody{ReadCloser: resp.Body, recorder: r, entry: entry, phases: &phases}
+	return resp, nil
+}
+
+func (r *HARRecorder) request(req *http.Request) harRequest {
+	record := harRequest{
+		Method:      req.Method,
+		URL:         req.URL.Redacted(),
+		HTTPVersion: req.Proto,
+		Cookies:     r.cookies(req.Cookies()),
+		Headers:     r.headers(req.Header),
+		QueryString: []harNameValue{},
+		HeadersSize: -1,
+		BodySize:    req.ContentLength,
+	}
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			record.QueryString = append(record.QueryString, harNameValue{Name: name, Value: value})
+		}
+	}
+
+	if r.config.MaxBodyBytes > 0 {
+		if body, _ := requestBody(req, r.config.MaxBodyBytes); body != nil {
+			record.PostData = &harPostData{MimeType: req.Header.Get("Content-Type"), Text: string(r.redact.body(body))}
+		}
+	}
+	return record
+}
+
+func (r *HARRecorder) response(resp *http.Response) harResponse {
+	return harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Cookies:     r.cookies(resp.Cookies()),
+		Headers:     r.headers(resp.Header),
+		Content:     harContent{Size: 0, MimeType: resp.Header.Get("Content-Type")},
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    resp.ContentLength,
+	}
+}
+
+func (r *HARRecorder) headers(h http.Header) []harNameValue {
+	records := []harNameValue{}
+	for name, values := range r.redact.header(h) {
+		for _, value := range values {
+			records = append(records, harNameValue{Name: name, Value: value})
+		}
+	}
+	return records
+}
+
+func (r *HARRecorder) cookies(cookies []*http.Cookie) []harCookie {
+	records := []harCookie{}
+	for _, cookie := range cookies {
+		// Cookie values are as sensitive as the Cookie headers they came from, so they're always masked
+		records = append(records, harCookie{
+			Name:     cookie.Name,
+			Value:    redactedValue,
+			Path:     cookie.Path,
+			Domain:   cookie.Domain,
+			HTTPOnly: cookie.HttpOnly,
+			Secure:   cookie.Secure,
+		})
+	}
+	return records
+}
+
+// harBody captures the response content and receive time as the body is consumed
+type harBody struct {
+	io.ReadCloser
+	recorder *HARRecorder
+	entry    *harEntry
+	phases   *harPhases
+	captured []byte
+	once     sync.Once
+}
+
+func (b *harBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if limit := b.recorder.config.MaxBodyBytes; n > 0 && len(b.captured) < limit {
+		b.captured = append(b.captured, p[:min(n, limit-len(b.captured))]...)
+	}
+
+	b.recorder.mu.Lock()
+	b.entry.Response.Content.Size += int64(n)
+	b.recorder.mu.Unlock()
+
+	if err == io.EOF {
+		b.complete()
+	}
+	return n, err
+}
+
+func (b *harBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.complete()
+	return err
+}
+
+// complete stores the captured content once the body was read or closed
+func (b *harBody) complete() {
+	b.once.Do(func() {
+		b.recorder.mu.Lock()
+		defer b.recorder.mu.Unlock()
+
+		b.entry.Timings.Receive = msSince(b.phases.headersAt)
+		b.entry.Time = b.entry.Timings.total()
+
+		if b.captured == nil {
+			return
+		}
+		if utf8.Valid(b.captured) {
+			b.entry.Response.Content.Text = string(b.recorder.redact.body(b.captured))
+		} else {
+			b.entry.Response.Content.Text = base64.StdEncoding.EncodeToString(b.captured)
+			b.entry.Response.Content.Encoding = "base64"
+		}
+	})
+}
+
+// harPhases tracks connection phases through httptrace
+type harPhases struct {
+	mu        sync.Mutex
+	start     time.Time
+	dnsStart  time.Time
+	connStart time.Time
+	tlsStart  time.Time
+	wrote     time.Time
+	headersAt time.Time
+	dns       float64
+	connect   float64
+	ssl       float64
+	blocked   float64
+}
+
+func (p *harPhases) trace() *httptrace.ClientTrace {
+	p.start = time.Now()
+	return &httptrace.ClientTrace{
+		GotConn: func(httptrace.GotConnInfo) {
+			p.mu.Lock()
+			p.blocked = msSince(p.start) - p.dns - p.connect - p.ssl
+			p.mu.Unlock()
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			p.mu.Lock()
+			p.dnsStart = time.Now()
+			p.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			p.mu.Lock()
+			p.dns = msSince(p.dnsStart)
+			p.mu.Unlock()
+		},
+		ConnectStart: func(string, string) {
+			p.mu.Lock()
+			if p.connStart.IsZero() {
+				p.connStart = time.Now()
+			}
+			p.mu.Unlock()
+		},
+		ConnectDone: func(string, string, error) {
+			p.mu.Lock()
+			p.connect = msSince(p.connStart)
+			p.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			p.mu.Lock()
+			p.tlsStart = time.Now()
+			p.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			p.mu.Lock()
+			p.ssl = msSince(p.tlsStart)
+			p.mu.Unlock()
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			p.mu.Lock()
+			p.wrote = time.Now()
+			p.mu.Unlock()
+		},
+	}
+}
+
+// timings converts the phases into HAR timings, with -1 for phases that didn't happen
+func (p *harPhases) timings(headersAt time.Time) harTimings {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t := harTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+	if p.dns > 0 {
+		t.DNS = p.dns
+	}
+	if p.connect > 0 {
+		// HAR counts the TLS handshake as part of connecting
+		t.Connect = p.connect + p.ssl
+	}
+	if p.ssl > 0 {
+		t.SSL = p.ssl
+	}
+	if p.blocked > 0 {
+		t.Blocked = p.blocked
+	}
+	if !p.wrote.IsZero() {
+		t.Wait = float64(headersAt.Sub(p.wrote)) / float64(time.Millisecond)
+	}
+	return t
+}
+
+func msSince(t time.Time) float64 {
+	return float64(time.Since(t)) / float64(time.Millisecond)
+}
+
+type harDocument struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harCookie    `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harCookie    `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// total sums the phases making up the entry time; ssl is already part of connect
+func (t harTimings) total() float64 {
+	total := t.Send + t.Wait + t.Receive
+	for _, phase := range []float64{t.Blocked, t.DNS, t.Connect} {
+		if phase > 0 {
+			total += phase
+		}
+	}
+	return total
+}
@@ -0,0 +1,177 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithHAR(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc123", Path: "/"})
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"token":"t0k3n","user":"alice"}`))
+			return
+		}
+		w.Write([]byte("profile"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "traffic.har")
+	rec := NewHARRecorder(HARConfig{Path: path, RedactFields: []string{"password", "token"}})
+	client := NewClient(server.URL, WithAuth(), WithHAR(rec))
+
+	resp, err := client.Post(context.Background(), "/login", map[string]string{"user": "alice", "password": "hunter2"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "t0k3n") {
+		t.Errorf("Expected recording to leave the body intact, got %q", body)
+	}
+
+	resp, err = client.Get(context.Background(), "/profile?tab=settings")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err := rec.Flush(); err != nil {
+		t.Fatalf("Failed to flush HAR: %v", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read HAR file: %v", err)
+	}
+
+	var doc harDocument
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("Invalid HAR document: %v", err)
+	}
+	if doc.Log.Version != "1.2" || len(doc.Log.Entries) != 2 {
+		t.Fatalf("Expected HAR 1.2 with 2 entries, got version %q with %d entries", doc.Log.Version, len(doc.Log.Entries))
+	}
+
+	login := doc.Log.Entries[0]
+	if login.Request.Method != "POST" || login.Request.PostData == nil {
+		t.Fatalf("Expected POST with post data, got %+v", login.Request)
+	}
+	if strings.Contains(login.Request.PostData.Text, "hunter2") || !strings.Contains(login.Request.PostData.Text, "alice") {
+		t.Errorf("Expected redacted request body, got %q", login.Request.PostData.Text)
+	}
+	if strings.Contains(login.Response.Content.Text, "t0k3n") {
+		t.Errorf("Expected redacted response body, got %q", login.Response.Content.Text)
+	}
+	if len(login.Response.Cookies) != 1 || login.Response.Cookies[0].Name != "session" || login.Response.Cookies[0].Value != redactedValue {
+		t.Errorf("Expected redacted response cookie, got %+v", login.Response.Cookies)
+	}
+	if login.Timings.Connect < 0 || login.Timings.Wait <= 0 {
+		t.Errorf("Expected connect and wait timings on the first request, got %+v", login.Timings)
+	}
+
+	profile := doc.Log.Entries[1]
+	if len(profile.Request.Cookies) != 1 || profile.Request.Cookies[0].Name != "session" {
+		t.Errorf("Expected the jar cookie in the request, got %+v", profile.Request.Cookies)
+	}
+	if len(profile.Request.QueryString) != 1 || profile.Request.QueryString[0].Value != "settings" {
+		t.Errorf("Expected query string, got %+v", profile.Request.QueryString)
+	}
+	if profile.Response.Content.Text != "profile" || profile.Response.Content.Size != 7 {
+		t.Errorf("Expected response content, got %+v", profile.Response.Content)
+	}
+	for _, h := range profile.Request.Headers {
+		if h.Name == "Cookie" && h.Value != redactedValue {
+			t.Errorf("Expected Cookie header to be redacted, got %q", h.Value)
+		}
+	}
+}
+
+func TestWithHAR_BodyLimitAndBinary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte{0xff, 0xfe, 0xfd, 0xfc, 0xfb, 0xfa})
+	}))
+	defer server.Close()
+
+	rec := NewHARRecorder(HARConfig{MaxBodyBytes: 4})
+	client := NewClient(server.URL, WithHAR(rec))
+
+	resp, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if len(body) != 6 {
+		t.Errorf("Expected full body, got %d bytes", len(body))
+	}
+
+	var buf bytes.Buffer
+	rec.WriteTo(&buf)
+	var doc harDocument
+	json.Unmarshal(buf.Bytes(), &doc)
+
+	content := doc.Log.Entries[0].Response.Content
+	if content.Encoding != "base64" || content.Text != "//79/A==" {
+		t.Errorf("Expected first 4 bytes base64 encoded, got %+v", content)
+	}
+	if content.Size != 6 {
+		t.Errorf("Expected content size 6, got %d", content.Size)
+	}
+}
+
+func TestWithHAR_TransportError(t *testing.T) {
+	rec := NewHARRecorder(HARConfig{})
+	client := NewClient(deadURL(), WithHAR(rec))
+
+	if _, err := client.Get(context.Background(), "/"); err == nil {
+		t.Fatal("Expected transport error, got none")
+	}
+
+	var buf bytes.Buffer
+	rec.WriteTo(&buf)
+	var doc harDocument
+	json.Unmarshal(buf.Bytes(), &doc)
+
+	if len(doc.Log.Entries) != 1 || doc.Log.Entries[0].Error == "" {
+		t.Errorf("Expected an entry recording the error, got %+v", doc.Log.Entries)
+	}
+	if err := rec.Flush(); err == nil {
+		t.Error("Expected Flush without a path to fail")
+	}
+}
+
+func TestHARRecorder_Reset(t *testing.T) {
+	server, _ := newNamedServer(t, "ok", http.StatusOK)
+	rec := NewHARRecorder(HARConfig{})
+	client := NewClient(server.URL, WithHAR(rec))
+
+	entries := func() int {
+		var buf bytes.Buffer
+		rec.WriteTo(&buf)
+		var doc harDocument
+		json.Unmarshal(buf.Bytes(), &doc)
+		return len(doc.Log.Entries)
+	}
+
+	getBody(t, client, "/")
+	getBody(t, client, "/")
+	if n := entries(); n != 2 {
+		t.Fatalf("Expected writing to keep 2 entries, got %d", n)
+	}
+
+	rec.Reset()
+	getBody(t, client, "/")
+	if n := entries(); n != 1 {
+		t.Errorf("Expected 1 entry recorded after Reset, got %d", n)
+	}
+}
@@ -0,0 +1,207 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HedgeConfig defines the configuration for hedged GET requests
+type HedgeConfig struct {
+	Delay      time.Duration // Wait before sending a hedge (default derived from observed latency)
+	Percentile float64       // Latency percentile used when Delay is zero (default 0.95)
+	MinDelay   time.Duration // Lower bound for the derived delay (default 10ms)
+	MaxHedges  int           // Extra attempts sent while earlier ones are outstanding (default 1)
+	BaseURL    string        // Send hedges to this base URL instead (default the original destination)
+}
+
+// hedgeMinSamples is how many latencies are observed before a derived delay is trusted
+const hedgeMinSamples = 20
+
+// hedgeWindow is how many recent latencies the derived delay is computed from
+const hedgeWindow = 256
+
+// WithHedging sends extra attempts of GET and HEAD requests that haven't responded after a delay.
+// The first successful response wins, the others are cancelled and their bodies drained.
+// Without a fixed Delay, hedging starts once enough latencies were observed to derive one.
+func WithHedging(config HedgeConfig) Option {
+	return func(c *Client) {
+		c.hedger = newHedger(config)
+	}
+}
+
+type hedger struct {
+	delay      time.Duration
+	percentile float64
+	minDelay   time.Duration
+	maxHedges  int
+	baseURL    string
+
+	mu        sync.Mutex
+	latencies []time.Duration
+	next      int
+}
+
+func newHedger(config HedgeConfig) *hedger {
+	if config.Percentile <= 0 || config.Percentile >= 1 {
+		config.Percentile = 0.95
+	}
+	if config.MinDelay <= 0 {
+		config.MinDelay = 10 * time.Millisecond
+	}
+	if config.MaxHedges <= 0 {
+		config.MaxHedges = 1
+	}
+	return &hedger{
+		delay:      config.Delay,
+		percentile: config.Percentile,
+		minDelay:   config.MinDelay,
+		maxHedges:  config.MaxHedges,
+		baseURL:    strings.TrimSuffix(config.BaseURL, "/"),
+		latencies:  make([]time.Duration, 0, hedgeWindow),
+	}
+}
+
+// hedgeResult is the outcome of one attempt
+type hedgeResult struct {
+	resp     *http.Response
+	err      error
+	index    int // 0 for the original attempt, hedges count up
+	cancel   context.CancelFunc
+	duration time.Duration
+}
+
+func (r hedgeResult) succeeded() bool {
+	return r.err == nil && r.resp.StatusCode < http.StatusInternalServerError
+}
+
+// do runs attempt, adding hedges while no attempt has succeeded, and returns the first success
+// or the last failure. attempt is told whether it is a hedge.
+func (h *hedger) do(ctx context.Context, attempt func(ctx context.Context, hedge bool) (*http.Response, error)) (*http.Response, error) {
+	delay, ok := h.currentDelay()
+	if !ok {
+		start := time.Now()
+		resp, err := attempt(ctx, false)
+		if (hedgeResult{resp: resp, err: err}).succeeded() {
+			h.record(time.Since(start))
+		}
+		return resp, err
+	}
+
+	results := make(chan hedgeResult, h.maxHedges+1)
+	var cancels []context.CancelFunc
+	pending := 0
+	launch := func() {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		pending++
+		go func() {
+			start := time.Now()
+			resp, err := attempt(attemptCtx, index > 0)
+			results <- hedgeResult{resp: resp, err: err, index: index, cancel: cancel, duration: time.Since(start)}
+		}()
+	}
+
+	launch()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var last *hedgeResult
+	for {
+		select {
+		case <-timer.C:
+			if len(cancels) <= h.maxHedges {
+				launch()
+				timer.Reset(delay)
+			}
+		case r := <-results:
+			pending--
+			if !r.succeeded() && pending > 0 {
+				// Another attempt may still succeed
+				if last != nil {
+					closeResult(*last)
+				}
+				last = &r
+				continue
+			}
+
+			if r.succeeded() {
+				h.record(r.duration)
+			}
+			if last != nil {
+				closeResult(*last)
+			}
+			h.discard(results, pending)
+
+			// The returned attempt's context stays alive until its body is closed
+			for i, cancel := range cancels {
+				if i != r.index {
+					cancel()
+				}
+			}
+			if r.err != nil {
+				r.cancel()
+				return nil, r.err
+			}
+			r.resp.Body = &releaseOnClose{ReadCloser: r.resp.Body, release: r.cancel}
+			return r.resp, nil
+		}
+	}
+}
+
+// discard drains the responses of attempts still outstanding in the background
+func (h *hedger) discard(results <-chan hedgeResult, pending int) {
+	go func() {
+		for i := 0; i < pending; i++ {
+			closeResult(<-results)
+		}
+	}()
+}
+
+// closeResult drains and closes the response of a losing attempt so its connection can be reused
+func closeResult(r hedgeResult) {
+	if r.resp == nil {
+		return
+	}
+	io.Copy(io.Discard, io.LimitReader(r.resp.Body, 64<<10))
+	r.resp.Body.Close()
+}
+
+// record adds a latency sample to the window
+func (h *hedger) record(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.latencies) < hedgeWindow {
+		h.latencies = append(h.latencies, d)
+		return
+	}
+	h.latencies[h.next] = d
+	h.next = (h.next + 1) % hedgeWindow
+}
+
+// currentDelay returns the hedge delay, false while too few latencies were observed to derive it
+func (h *hedger) currentDelay() (time.Duration, bool) {
+	if h.delay > 0 {
+		return h.delay, true
+	}
+
+	h.mu.Lock()
+	sorted := append([]time.Duration(nil), h.latencies...)
+	h.mu.Unlock()
+	if len(sorted) < hedgeMinSamples {
+		return 0, false
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	delay := sorted[int(float64(len(sorted)-1)*h.percentile)]
+	if delay < h.minDelay {
+		delay = h.minDelay
+	}
+	return delay, true
+}
@@ -0,0 +1,145 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithHedging(t *testing.T) {
+	var hits, cancelled int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+				atomic.AddInt32(&cancelled, 1)
+				return
+			case <-time.After(2 * time.Second):
+			}
+		}
+		w.Write([]byte("fast"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithHedging(HedgeConfig{Delay: 20 * time.Millisecond}))
+
+	start := time.Now()
+	resp, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != "fast" {
+		t.Errorf("Expected the hedge to win, got %q", body)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected hedging to cut latency, took %v", elapsed)
+	}
+	if hits != 2 {
+		t.Errorf("Expected 2 attempts, got %d", hits)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&cancelled) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if atomic.LoadInt32(&cancelled) != 1 {
+		t.Error("Expected the losing attempt to be cancelled")
+	}
+}
+
+func TestWithHedging_FastResponseNotHedged(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithHedging(HedgeConfig{Delay: 200 * time.Millisecond}))
+
+	for _, do := range []func() (*http.Response, error){
+		func() (*http.Response, error) { return client.Get(context.Background(), "/") },
+		func() (*http.Response, error) { return client.Post(context.Background(), "/", nil) },
+	} {
+		resp, err := do()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if hits != 2 {
+		t.Errorf("Expected no hedges, got %d hits", hits)
+	}
+}
+
+func TestWithHedging_BaseURL(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer slow.Close()
+	backup, backupHits := newNamedServer(t, "backup", http.StatusOK)
+
+	client := NewClient(slow.URL, WithHedging(HedgeConfig{Delay: 20 * time.Millisecond, BaseURL: backup.URL}))
+
+	if body, _ := getBody(t, client, "/"); body != "backup" {
+		t.Errorf("Expected the hedge to be sent to the backup, got %q", body)
+	}
+	if *backupHits != 1 {
+		t.Errorf("Expected 1 backup hit, got %d", *backupHits)
+	}
+}
+
+func TestWithHedging_ServerErrorWaitsForHedge(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			time.Sleep(40 * time.Millisecond)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		time.Sleep(60 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithHedging(HedgeConfig{Delay: 10 * time.Millisecond}))
+
+	resp, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the successful hedge to win over a 503, got %d", resp.StatusCode)
+	}
+}
+
+func TestHedger_DerivedDelay(t *testing.T) {
+	h := newHedger(HedgeConfig{})
+	if _, ok := h.currentDelay(); ok {
+		t.Error("Expected no delay before enough samples")
+	}
+
+	for i := 1; i <= 100; i++ {
+		h.record(time.Duration(i) * time.Millisecond)
+	}
+	if delay, ok := h.currentDelay(); !ok || delay != 95*time.Millisecond {
+		t.Errorf("Expected p95 delay of 95ms, got %v", delay)
+	}
+
+	h = newHedger(HedgeConfig{MinDelay: 50 * time.Millisecond})
+	for i := 0; i < hedgeMinSamples; i++ {
+		h.record(time.Millisecond)
+	}
+	if delay, _ := h.currentDelay(); delay != 50*time.Millisecond {
+		t.Errorf("Expected the delay to be clamped to 50ms, got %v", delay)
+	}
+}
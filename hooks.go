@@ -0,0 +1,79 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+)
+
+// BeforeRequestHook runs before a request is sent, returning an error aborts it
+type BeforeRequestHook func(*http.Request) error
+
+// AfterResponseHook runs after a request completes with its response or transport error.
+// Returning an error fails the request with that error.
+// Hook errors are not retried and don't count against endpoint health or the adaptive limit.
+type AfterResponseHook func(*http.Response, error) error
+
+// OnBeforeRequest registers a hook that runs before every request, including CachedClient refreshes
+func (c *Client) OnBeforeRequest(hook BeforeRequestHook) {
+	c.hooksMux.Lock()
+	c.beforeHooks = append(c.beforeHooks, hook)
+	c.hooksMux.Unlock()
+}
+
+// OnAfterResponse registers a hook that runs after every request, including CachedClient refreshes
+func (c *Client) OnAfterResponse(hook AfterResponseHook) {
+	c.hooksMux.Lock()
+	c.afterHooks = append(c.afterHooks, hook)
+	c.hooksMux.Unlock()
+}
+
+// hookError marks an error returned by a hook. It says nothing about the upstream,
+// so the balancer, retries and the adaptive limit ignore it.
+type hookError struct {
+	err error
+}
+
+func (e *hookError) Error() string { return e.err.Error() }
+
+func (e *hookError) Unwrap() error { return e.err }
+
+// isHookError reports whether err was returned by a hook
+func isHookError(err error) bool {
+	var hookErr *hookError
+	return errors.As(err, &hookErr)
+}
+
+// runBeforeHooks stops at the first hook returning an error
+func (c *Client) runBeforeHooks(req *http.Request) error {
+	c.hooksMux.RLock()
+	hooks := c.beforeHooks
+	c.hooksMux.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(req); err != nil {
+			return &hookError{err: err}
+		}
+	}
+	return nil
+}
+
+// runAfterHooks stops at the first hook returning an error, discarding the response
+func (c *Client) runAfterHooks(resp *http.Response, err error) (*http.Response, error) {
+	c.hooksMux.RLock()
+	hooks := c.afterHooks
+	c.hooksMux.RUnlock()
+
+	for _, hook := range hooks {
+		if hookErr := hook(resp, err); hookErr != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			// A hook passing on the transport error keeps it visible to health tracking
+			if err != nil && errors.Is(hookErr, err) {
+				return nil, hookErr
+			}
+			return nil, &hookError{err: hookErr}
+		}
+	}
+	return resp, err
+}
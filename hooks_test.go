@@ -0,0 +1,198 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_OnBeforeRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Signature") != "signed" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.OnBeforeRequest(func(req *http.Request) error {
+		req.Header.Set("X-Signature", "signed")
+		return nil
+	})
+
+	resp, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected hook to sign the request, got status %d", resp.StatusCode)
+	}
+}
+
+func TestClient_OnBeforeRequest_Abort(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+
+	errReadOnly := errors.New("client is read-only")
+	client := NewClient(server.URL)
+	client.OnBeforeRequest(func(req *http.Request) error {
+		if req.Method != http.MethodGet {
+			return errReadOnly
+		}
+		return nil
+	})
+
+	if _, err := client.Delete(context.Background(), "/users/1"); !errors.Is(err, errReadOnly) {
+		t.Errorf("Expected read-only error, got %v", err)
+	}
+	if hits != 0 {
+		t.Errorf("Expected aborted request not to reach the server, got %d hits", hits)
+	}
+}
+
+func TestClient_OnAfterResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	var seen int32
+	client.OnAfterResponse(func(resp *http.Response, err error) error {
+		atomic.AddInt32(&seen, 1)
+		return nil
+	})
+	client.OnAfterResponse(func(resp *http.Response, err error) error {
+		if err == nil && resp.StatusCode >= 400 {
+			return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		}
+		return nil
+	})
+
+	resp, err := client.Get(context.Background(), "/")
+	if err == nil {
+		t.Fatal("Expected hook to convert the response into an error")
+	}
+	if resp != nil {
+		t.Error("Expected no response alongside the hook error")
+	}
+	if seen != 1 {
+		t.Errorf("Expected earlier hooks to run, got %d calls", seen)
+	}
+}
+
+func TestClient_OnAfterResponse_TransportError(t *testing.T) {
+	client := NewClient(deadURL())
+
+	var gotErr error
+	client.OnAfterResponse(func(resp *http.Response, err error) error {
+		gotErr = err
+		return nil
+	})
+
+	if _, err := client.Get(context.Background(), "/"); err == nil {
+		t.Fatal("Expected transport error, got none")
+	}
+	if gotErr == nil {
+		t.Error("Expected hook to observe the transport error")
+	}
+}
+
+func TestCachedClient_Hooks(t *testing.T) {
+	server, client := setupCachedTestServer(t, nil)
+	defer server.Close()
+	defer client.Stop()
+
+	var before, after int32
+	client.OnBeforeRequest(func(req *http.Request) error {
+		atomic.AddInt32(&before, 1)
+		return nil
+	})
+	client.OnAfterResponse(func(resp *http.Response, err error) error {
+		atomic.AddInt32(&after, 1)
+		return nil
+	})
+
+	data := &TestCacheData{}
+	err := client.SetupCachedEndpoint(context.Background(), CacheConfig{
+		Path:       "/test",
+		CronSpec:   "@every 50ms",
+		Expiration: time.Minute,
+	}, data)
+	if err != nil {
+		t.Fatalf("Failed to setup cached endpoint: %v", err)
+	}
+
+	time.Sleep(120 * time.Millisecond)
+
+	b, a := atomic.LoadInt32(&before), atomic.LoadInt32(&after)
+	if b < 2 || a < 2 {
+		t.Errorf("Expected hooks on initial fetch and refreshes, got before=%d after=%d", b, a)
+	}
+}
+
+func TestHooks_WithLoadBalancer(t *testing.T) {
+	var endpoints []Endpoint
+	var hits []*int32
+	for _, name := range []string{"a", "b", "c"} {
+		server, h := newNamedServer(t, name, http.StatusNotFound)
+		endpoints = append(endpoints, Endpoint{URL: server.URL})
+		hits = append(hits, h)
+	}
+
+	client := NewClient("",
+		WithLoadBalancer(LoadBalancerConfig{Endpoints: endpoints, MaxFailures: 1}),
+		WithAdaptiveLimit(AdaptiveLimitConfig{InitialLimit: 50}),
+	)
+	errNotFound := errors.New("not found")
+	client.OnAfterResponse(func(resp *http.Response, err error) error {
+		if err == nil && resp.StatusCode == http.StatusNotFound {
+			return errNotFound
+		}
+		return nil
+	})
+
+	for i := 0; i < 30; i++ {
+		if _, err := client.Get(context.Background(), "/"); !errors.Is(err, errNotFound) {
+			t.Fatalf("Expected the hook error, got %v", err)
+		}
+	}
+
+	var total int32
+	for _, h := range hits {
+		total += atomic.LoadInt32(h)
+	}
+	if total != 30 {
+		t.Errorf("Expected hook errors not to be retried on other endpoints, got %d hits for 30 calls", total)
+	}
+	for _, e := range client.balancer.endpoints {
+		if !e.ejectedUntil.IsZero() {
+			t.Errorf("Expected %s not to be ejected for hook errors", e.url)
+		}
+	}
+	if limit := client.ConcurrencyLimit(); limit != 50 {
+		t.Errorf("Expected hook errors to leave the adaptive limit at 50, got %d", limit)
+	}
+
+	client.OnBeforeRequest(func(req *http.Request) error {
+		return errors.New("aborted")
+	})
+	client.Get(context.Background(), "/")
+	total = 0
+	for _, h := range hits {
+		total += atomic.LoadInt32(h)
+	}
+	if total != 30 {
+		t.Errorf("Expected an aborted request not to reach any endpoint, got %d hits", total-30)
+	}
+}
@@ -0,0 +1,296 @@
+// Package httpclienttest provides a programmable mock transport for testing code built on httpclient.
+//
+// Usage:
+//
+//	mock := httpclienttest.NewMockTransport(t)
+//	mock.Expect(http.MethodGet, "/users/1").RespondJSON(http.StatusOK, User{ID: 1})
+//
+//	client := httpclient.NewClient("https://api.example.com", httpclient.WithTransport(mock))
+//
+// Expectations that were not met are reported to t when the test finishes.
+package httpclienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// MockTransport is an http.RoundTripper answering requests from registered expectations
+type MockTransport struct {
+	t       testing.TB
+	mu      sync.Mutex
+	ordered bool
+
+	expectations []*Expectation
+	calls        []*http.Request
+}
+
+// NewMockTransport creates a mock transport that checks its expectations when the test finishes
+func NewMockTransport(t testing.TB) *MockTransport {
+	m := &MockTransport{t: t}
+	t.Cleanup(m.AssertExpectations)
+	return m
+}
+
+// InOrder requires expectations to be met in the order they were registered
+func (m *MockTransport) InOrder() *MockTransport {
+	m.mu.Lock()
+	m.ordered = true
+	m.mu.Unlock()
+	return m
+}
+
+// Expect registers an expectation for a request with the given method and URL path.
+// By default it must be met exactly once and answers with 200 OK.
+func (m *MockTransport) Expect(method, path string) *Expectation {
+	e := &Expectation{
+		method:      method,
+		path:        path,
+		query:       make(map[string]string),
+		header:      make(map[string]string),
+		times:       1,
+		status:      http.StatusOK,
+		respHeaders: http.Header{},
+	}
+
+	m.mu.Lock()
+	m.expectations = append(m.expectations, e)
+	m.mu.Unlock()
+	return e
+}
+
+// Calls returns every request received so far
+func (m *MockTransport) Calls() []*http.Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*http.Request(nil), m.calls...)
+}
+
+// AssertExpectations reports expectations that were called fewer times than required
+func (m *MockTransport) AssertExpectations() {
+	m.t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.expectations {
+		if e.times >= 0 && e.calls < e.times {
+			m.t.Errorf("httpclienttest: expected %s to be called %d times, got %d", e, e.times, e.calls)
+		}
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+	}
+
+	e, err := m.match(req, body)
+	if err != nil {
+		m.t.Errorf("httpclienttest: %v", err)
+		return nil, err
+	}
+
+	if e.delay > 0 {
+		timer := time.NewTimer(e.delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(e.status) + " " + http.StatusText(e.status),
+		StatusCode:    e.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.respHeaders.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.respBody)),
+		ContentLength: int64(len(e.respBody)),
+		Request:       req,
+	}, nil
+}
+
+// match finds the expectation for req and records the call
+func (m *MockTransport) match(req *http.Request, body []byte) (*Expectation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, req)
+
+	for _, e := range m.expectations {
+		if !e.exhausted() && e.matches(req, body) {
+			if m.ordered {
+				if pending := m.firstPending(); pending != nil && pending != e {
+					return nil, fmt.Errorf("%s %s called out of order, expected %s first", req.Method, req.URL.Path, pending)
+				}
+			}
+			e.calls++
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected request %s %s", req.Method, req.URL)
+}
+
+// firstPending returns the earliest expectation still requiring calls
+func (m *MockTransport) firstPending() *Expectation {
+	for _, e := range m.expectations {
+		if e.times < 0 {
+			if e.calls == 0 {
+				return e
+			}
+			continue
+		}
+		if e.calls < e.times {
+			return e
+		}
+	}
+	return nil
+}
+
+// Expectation describes a request to expect and how to answer it
+type Expectation struct {
+	method string
+	path   string
+	query  map[string]string
+	header map[string]string
+	body   func([]byte) bool
+	times  int // negative means any number of times
+	calls  int
+
+	status      int
+	respHeaders http.Header
+	respBody    []byte
+	err         error
+	delay       time.Duration
+}
+
+// WithQuery requires a query parameter value
+func (e *Expectation) WithQuery(key, value string) *Expectation {
+	e.query[key] = value
+	return e
+}
+
+// WithHeader requires a request header value
+func (e *Expectation) WithHeader(key, value string) *Expectation {
+	e.header[key] = value
+	return e
+}
+
+// WithBody requires the request body to satisfy matcher
+func (e *Expectation) WithBody(matcher func(body []byte) bool) *Expectation {
+	e.body = matcher
+	return e
+}
+
+// WithJSONBody requires the request body to be JSON equal to v
+func (e *Expectation) WithJSONBody(v interface{}) *Expectation {
+	expected, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("httpclienttest: failed to marshal expected body: %v", err))
+	}
+	return e.WithBody(func(body []byte) bool {
+		var want, got interface{}
+		if json.Unmarshal(expected, &want) != nil || json.Unmarshal(body, &got) != nil {
+			return false
+		}
+		return reflect.DeepEqual(want, got)
+	})
+}
+
+// Times sets how many calls are expected, matching stops once reached
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// AnyTimes allows any number of calls, including none
+func (e *Expectation) AnyTimes() *Expectation {
+	e.times = -1
+	return e
+}
+
+// Respond answers with status and body
+func (e *Expectation) Respond(status int, body string) *Expectation {
+	e.status = status
+	e.respBody = []byte(body)
+	return e
+}
+
+// RespondJSON answers with status and v encoded as JSON
+func (e *Expectation) RespondJSON(status int, v interface{}) *Expectation {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("httpclienttest: failed to marshal response: %v", err))
+	}
+	e.respHeaders.Set("Content-Type", "application/json")
+	return e.Respond(status, string(body))
+}
+
+// RespondHeader adds a response header
+func (e *Expectation) RespondHeader(key, value string) *Expectation {
+	e.respHeaders.Add(key, value)
+	return e
+}
+
+// ReturnError fails the request with err instead of responding
+func (e *Expectation) ReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// Delay waits before answering, returning early if the request is cancelled
+func (e *Expectation) Delay(d time.Duration) *Expectation {
+	e.delay = d
+	return e
+}
+
+func (e *Expectation) exhausted() bool {
+	return e.times >= 0 && e.calls >= e.times
+}
+
+func (e *Expectation) matches(req *http.Request, body []byte) bool {
+	if req.Method != e.method || req.URL.Path != e.path {
+		return false
+	}
+	query := req.URL.Query()
+	for k, v := range e.query {
+		if query.Get(k) != v {
+			return false
+		}
+	}
+	for k, v := range e.header {
+		if req.Header.Get(k) != v {
+			return false
+		}
+	}
+	return e.body == nil || e.body(body)
+}
+
+func (e *Expectation) String() string {
+	var conditions []string
+	for k, v := range e.query {
+		conditions = append(conditions, k+"="+v)
+	}
+	if len(conditions) == 0 {
+		return e.method + " " + e.path
+	}
+	return e.method + " " + e.path + "?" + strings.Join(conditions, "&")
+}
@@ -0,0 +1,170 @@
+package httpclienttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samhoque/httpclient"
+)
+
+// recordingTB captures failures so tests can assert on them
+type recordingTB struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Cleanup(fn func()) {
+	r.cleanups = append(r.cleanups, fn)
+}
+
+func (r *recordingTB) finish() {
+	for _, fn := range r.cleanups {
+		fn()
+	}
+}
+
+func readBody(t *testing.T, resp *http.Response, err error) string {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestMockTransport(t *testing.T) {
+	mock := NewMockTransport(t)
+	mock.Expect(http.MethodGet, "/users").WithQuery("page", "2").RespondJSON(http.StatusOK, []string{"alice"})
+	mock.Expect(http.MethodPost, "/users").
+		WithHeader("X-Request-ID", "abc").
+		WithJSONBody(map[string]string{"name": "bob"}).
+		Respond(http.StatusCreated, "created").
+		RespondHeader("Location", "/users/2")
+
+	client := httpclient.NewClient("https://api.example.com", httpclient.WithTransport(mock))
+
+	resp, err := client.Get(context.Background(), "/users?page=2")
+	if resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected JSON content type, got %q", resp.Header.Get("Content-Type"))
+	}
+	if body := readBody(t, resp, err); body != `["alice"]` {
+		t.Errorf("Expected canned body, got %q", body)
+	}
+
+	resp, err = client.Post(context.Background(), "/users", map[string]string{"name": "bob"},
+		httpclient.WithRequestHeader("X-Request-ID", "abc"))
+	if body := readBody(t, resp, err); body != "created" || resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected 201 created, got %d %q", resp.StatusCode, body)
+	}
+	if resp.Header.Get("Location") != "/users/2" {
+		t.Errorf("Expected Location header, got %q", resp.Header.Get("Location"))
+	}
+
+	if calls := mock.Calls(); len(calls) != 2 {
+		t.Errorf("Expected 2 calls, got %d", len(calls))
+	}
+}
+
+func TestMockTransport_ErrorsAndDelay(t *testing.T) {
+	mock := NewMockTransport(t)
+	failure := errors.New("connection reset")
+	mock.Expect(http.MethodGet, "/fail").ReturnError(failure)
+	mock.Expect(http.MethodGet, "/slow").Delay(time.Second)
+
+	client := httpclient.NewClient("https://api.example.com", httpclient.WithTransport(mock))
+
+	if _, err := client.Get(context.Background(), "/fail"); !errors.Is(err, failure) {
+		t.Errorf("Expected canned error, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.Get(ctx, "/slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected delay to respect cancellation, took %v", elapsed)
+	}
+}
+
+func TestMockTransport_Times(t *testing.T) {
+	mock := NewMockTransport(t)
+	mock.Expect(http.MethodGet, "/poll").Times(2).Respond(http.StatusAccepted, "")
+	mock.Expect(http.MethodGet, "/poll").Respond(http.StatusOK, "done")
+	mock.Expect(http.MethodGet, "/health").AnyTimes()
+
+	client := httpclient.NewClient("https://api.example.com", httpclient.WithTransport(mock))
+
+	var statuses []int
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(context.Background(), "/poll")
+		readBody(t, resp, err)
+		statuses = append(statuses, resp.StatusCode)
+	}
+	if fmt.Sprint(statuses) != "[202 202 200]" {
+		t.Errorf("Expected expectations to be used up in order, got %v", statuses)
+	}
+}
+
+func TestMockTransport_ReportsFailures(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	mock := NewMockTransport(tb)
+	mock.Expect(http.MethodGet, "/called")
+	mock.Expect(http.MethodGet, "/missing").Times(2)
+
+	client := httpclient.NewClient("https://api.example.com", httpclient.WithTransport(mock))
+
+	resp, err := client.Get(context.Background(), "/called")
+	readBody(t, resp, err)
+	if _, err := client.Get(context.Background(), "/unknown"); err == nil {
+		t.Error("Expected unexpected request to fail")
+	}
+	tb.finish()
+
+	if len(tb.errors) != 2 {
+		t.Fatalf("Expected 2 reported failures, got %v", tb.errors)
+	}
+	if !strings.Contains(tb.errors[0], "unexpected request GET") {
+		t.Errorf("Expected unexpected request report, got %q", tb.errors[0])
+	}
+	if !strings.Contains(tb.errors[1], "GET /missing to be called 2 times, got 0") {
+		t.Errorf("Expected unmet expectation report, got %q", tb.errors[1])
+	}
+}
+
+func TestMockTransport_InOrder(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	mock := NewMockTransport(tb).InOrder()
+	mock.Expect(http.MethodPost, "/login")
+	mock.Expect(http.MethodGet, "/profile")
+
+	client := httpclient.NewClient("https://api.example.com", httpclient.WithTransport(mock))
+
+	if _, err := client.Get(context.Background(), "/profile"); err == nil {
+		t.Error("Expected out of order request to fail")
+	}
+	resp, err := client.Post(context.Background(), "/login", nil)
+	readBody(t, resp, err)
+	resp, err = client.Get(context.Background(), "/profile")
+	readBody(t, resp, err)
+	tb.finish()
+
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "out of order, expected POST /login first") {
+		t.Errorf("Expected a single ordering failure, got %v", tb.errors)
+	}
+}
@@ -0,0 +1,53 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// IdempotencyKeyHeader carries the key identifying one logical call across retries
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WithIdempotencyKeys adds an Idempotency-Key header to POST and PATCH requests.
+// The key is generated once per call and reused by every retry, which makes these
+// methods safe to retry after transport errors. generate defaults to random UUIDs.
+// A key set with WithRequestHeader takes precedence.
+func WithIdempotencyKeys(generate func() string) Option {
+	return func(c *Client) {
+		if generate == nil {
+			generate = newUUID
+		}
+		c.idempotencyKeys = generate
+	}
+}
+
+// withIdempotencyKey generates the call's key and prepends the header so request options can override it
+func (c *Client) withIdempotencyKey(ctx context.Context, method string, opts []RequestOption) []RequestOption {
+	if c.idempotencyKeys == nil || (method != http.MethodPost && method != http.MethodPatch) {
+		return opts
+	}
+
+	key := c.idempotencyKeys()
+	callStateFrom(ctx).idempotencyKey = key
+	keyed := func(req *http.Request) {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	return append([]RequestOption{keyed}, opts...)
+}
+
+// hasIdempotencyKey reports whether the call carries a key making it safe to retry
+func hasIdempotencyKey(ctx context.Context) bool {
+	state := callStateFrom(ctx)
+	return state != nil && state.idempotencyKey != ""
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
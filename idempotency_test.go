@@ -0,0 +1,114 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// newKeyServer records the Idempotency-Key of every request, closing the connection instead of answering when fail is set
+func newKeyServer(t *testing.T, fail bool) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		mu.Unlock()
+		if fail {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), keys...)
+	}
+}
+
+func TestWithIdempotencyKeys(t *testing.T) {
+	server, keys := newKeyServer(t, false)
+	client := NewClient(server.URL, WithIdempotencyKeys(nil))
+	ctx := context.Background()
+
+	for _, do := range []func() (*http.Response, error){
+		func() (*http.Response, error) { return client.Post(ctx, "/", nil) },
+		func() (*http.Response, error) { return client.Patch(ctx, "/", nil) },
+		func() (*http.Response, error) { return client.Put(ctx, "/", nil) },
+		func() (*http.Response, error) { return client.Get(ctx, "/") },
+		func() (*http.Response, error) {
+			return client.Post(ctx, "/", nil, WithRequestHeader(IdempotencyKeyHeader, "mine"))
+		},
+	} {
+		resp, err := do()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	got := keys()
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !uuid.MatchString(got[0]) || !uuid.MatchString(got[1]) || got[0] == got[1] {
+		t.Errorf("Expected distinct UUID keys for POST and PATCH, got %q and %q", got[0], got[1])
+	}
+	if got[2] != "" || got[3] != "" {
+		t.Errorf("Expected no key for PUT and GET, got %q and %q", got[2], got[3])
+	}
+	if got[4] != "mine" {
+		t.Errorf("Expected an explicit key to take precedence, got %q", got[4])
+	}
+}
+
+func TestWithIdempotencyKeys_StableAcrossRetries(t *testing.T) {
+	failing, failingKeys := newKeyServer(t, true)
+	healthy, healthyKeys := newKeyServer(t, false)
+
+	n := 0
+	client := NewClient("",
+		WithLoadBalancer(LoadBalancerConfig{
+			Strategy:  Failover,
+			Endpoints: []Endpoint{{URL: failing.URL}, {URL: healthy.URL, Priority: 1}},
+		}),
+		WithIdempotencyKeys(func() string {
+			n++
+			return "key-" + strconv.Itoa(n)
+		}),
+	)
+
+	resp, err := client.Post(context.Background(), "/orders", map[string]int{"qty": 1})
+	if err != nil {
+		t.Fatalf("Expected the keyed POST to be retried, got %v", err)
+	}
+	resp.Body.Close()
+
+	if got := failingKeys(); len(got) == 0 || got[0] != "key-1" {
+		t.Errorf("Expected the failed attempt to carry key-1, got %v", got)
+	}
+	if got := healthyKeys(); len(got) != 1 || got[0] != "key-1" {
+		t.Errorf("Expected the retry to reuse key-1, got %v", got)
+	}
+}
+
+func TestPost_NotRetriedWithoutIdempotencyKey(t *testing.T) {
+	failing, _ := newKeyServer(t, true)
+	healthy, healthyKeys := newKeyServer(t, false)
+
+	client := NewClient("", WithLoadBalancer(LoadBalancerConfig{
+		Strategy:  Failover,
+		Endpoints: []Endpoint{{URL: failing.URL}, {URL: healthy.URL, Priority: 1}},
+	}))
+
+	if _, err := client.Post(context.Background(), "/orders", nil); err == nil {
+		t.Fatal("Expected the POST to fail without retry")
+	}
+	if got := healthyKeys(); len(got) != 0 {
+		t.Errorf("Expected no retry, got %d requests", len(got))
+	}
+}
@@ -0,0 +1,97 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// ErrResponseTooLarge matches every ResponseTooLargeError
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// ResponseTooLargeError is returned when reading a response body past the configured limit
+type ResponseTooLargeError struct {
+	Limit int64 // Limit in bytes that was exceeded
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return ErrResponseTooLarge.Error() + ": exceeds " + strconv.FormatInt(e.Limit, 10) + " bytes"
+}
+
+// Is makes errors.Is(err, ErrResponseTooLarge) match
+func (e *ResponseTooLargeError) Is(target error) bool {
+	return target == ErrResponseTooLarge
+}
+
+// WithMaxResponseBytes limits how much of a response body can be read. Reads past the limit,
+// including those made by CachedClient and singleflight, fail with a ResponseTooLargeError.
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *Client) {
+		c.maxResponseBytes = n
+	}
+}
+
+// WithRequestMaxResponseBytes overrides the response body limit for a single request,
+// n <= 0 removes the limit
+func WithRequestMaxResponseBytes(n int64) RequestOption {
+	return func(req *http.Request) {
+		if state := callStateFrom(req.Context()); state != nil {
+			if n <= 0 {
+				n = -1 // Set but unlimited, so the client limit doesn't apply
+			}
+			state.maxResponseBytes = n
+		}
+	}
+}
+
+// responseLimit returns the body limit for a request, 0 if it is unlimited
+func (c *Client) responseLimit(ctx context.Context) int64 {
+	if state := callStateFrom(ctx); state != nil && state.maxResponseBytes != 0 {
+		return max(state.maxResponseBytes, 0)
+	}
+	return max(c.maxResponseBytes, 0)
+}
+
+// limitBody wraps the response body so reads fail once the limit is exceeded
+func (c *Client) limitBody(req *http.Request, resp *http.Response) {
+	limit := c.responseLimit(req.Context())
+	if limit == 0 || resp.Body == nil || resp.Body == http.NoBody {
+		return
+	}
+
+	body := &limitedBody{ReadCloser: resp.Body, limit: limit, remaining: limit}
+	// Fail before reading anything when the server announces an oversized body
+	if resp.ContentLength > limit {
+		body.err = &ResponseTooLargeError{Limit: limit}
+	}
+	resp.Body = body
+}
+
+// limitedBody reads at most limit bytes, one more byte is read to detect an oversized body
+type limitedBody struct {
+	io.ReadCloser
+	limit     int64
+	remaining int64
+	err       error
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		b.err = &ResponseTooLargeError{Limit: b.limit}
+		return n, b.err
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
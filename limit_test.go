@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newBodyServer serves size bytes, streamed without a Content-Length unless announce is set
+func newBodyServer(t *testing.T, size int, announce bool) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if announce {
+			w.Header().Set("Content-Length", "100")
+		}
+		for i := 0; i < size/10; i++ {
+			io.WriteString(w, strings.Repeat("x", 10))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWithMaxResponseBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		announce bool
+		expected int
+		tooLarge bool
+	}{
+		{name: "within limit", size: 50, expected: 50},
+		{name: "at limit", size: 60, expected: 60},
+		{name: "streamed past limit", size: 100, expected: 60, tooLarge: true},
+		{name: "announced past limit", size: 100, announce: true, expected: 0, tooLarge: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(newBodyServer(t, tt.size, tt.announce).URL, WithMaxResponseBytes(60))
+
+			resp, err := client.Get(context.Background(), "/")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if len(body) != tt.expected {
+				t.Errorf("Expected %d bytes, got %d", tt.expected, len(body))
+			}
+			if !tt.tooLarge {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+
+			var tooLarge *ResponseTooLargeError
+			if !errors.Is(err, ErrResponseTooLarge) || !errors.As(err, &tooLarge) || tooLarge.Limit != 60 {
+				t.Errorf("Expected a ResponseTooLargeError with limit 60, got %v", err)
+			}
+			if _, err := resp.Body.Read(make([]byte, 1)); !errors.Is(err, ErrResponseTooLarge) {
+				t.Errorf("Expected later reads to keep failing, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWithRequestMaxResponseBytes(t *testing.T) {
+	client := NewClient(newBodyServer(t, 100, false).URL, WithMaxResponseBytes(10))
+
+	tests := []struct {
+		name     string
+		limit    int64
+		tooLarge bool
+	}{
+		{name: "raised", limit: 200},
+		{name: "lowered", limit: 5, tooLarge: true},
+		{name: "removed", limit: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Get(context.Background(), "/", WithRequestMaxResponseBytes(tt.limit))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			_, err = io.ReadAll(resp.Body)
+			if tt.tooLarge != errors.Is(err, ErrResponseTooLarge) {
+				t.Errorf("Expected too large %v, got %v", tt.tooLarge, err)
+			}
+		})
+	}
+}
+
+func TestCachedClient_MaxResponseBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"value":"`+strings.Repeat("x", 100)+`"}`)
+	}))
+	defer server.Close()
+
+	client := NewCachedClient(server.URL)
+	defer client.Stop()
+
+	var result TestCacheData
+	err := client.SetupCachedEndpoint(context.Background(), CacheConfig{
+		Path:             "/data",
+		CronSpec:         "@every 1h",
+		MaxResponseBytes: 32,
+	}, &result)
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package httpclient
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// LogConfig defines what the request logger records
+type LogConfig struct {
+	Level         slog.Leveler // Level for completed requests (default slog.LevelInfo)
+	ErrorLevel    slog.Leveler // Level for transport errors and 5xx responses (default slog.LevelError)
+	LogHeaders    bool         // Include request and response headers
+	LogBodies     bool         // Include request and response bodies
+	MaxBodyBytes  int          // Maximum logged bytes per body (default 4096)
+	RedactHeaders []string     // Extra headers to mask, Authorization and cookies are always masked
+	RedactFields  []string     // JSON field names whose values are masked in logged bodies
+}
+
+// WithLogger logs every request and CachedClient refresh failures to logger
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithLogConfig configures the levels, headers, bodies and redaction used by WithLogger
+func WithLogConfig(config LogConfig) Option {
+	return func(c *Client) {
+		c.logConfig = config
+	}
+}
+
+// log returns the configured logger, falling back to the default one for refresh errors
+func (c *Client) log() *slog.Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	return slog.Default()
+}
+
+// loggingMiddleware logs one record per completed request
+func (c *Client) loggingMiddleware() Middleware {
+	config := c.logConfig
+	if config.Level == nil {
+		config.Level = slog.LevelInfo
+	}
+	if config.ErrorLevel == nil {
+		config.ErrorLevel = slog.LevelError
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = 4096
+	}
+	redact := newRedactor(config.RedactHeaders, config.RedactFields)
+	logger := c.logger
+
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			start := time.Now()
+
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("url", req.URL.Redacted()),
+				slog.Int64("request_size", req.ContentLength),
+			}
+			if config.LogHeaders {
+				attrs = append(attrs, slog.Any("request_headers", redact.header(req.Header)))
+			}
+			if config.LogBodies {
+				if body, truncated := requestBody(req, config.MaxBodyBytes); body != nil {
+					attrs = append(attrs, bodyAttr("request_body", redact.body(body), truncated))
+				}
+			}
+
+			resp, err := next(req)
+			attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+
+			if err != nil {
+				attrs = append(attrs, slog.Any("error", err))
+				logger.LogAttrs(ctx, config.ErrorLevel.Level(), "http request failed", attrs...)
+				return resp, err
+			}
+
+			attrs = append(attrs,
+				slog.Int("status", resp.StatusCode),
+				slog.Int64("response_size", resp.ContentLength),
+			)
+			if config.LogHeaders {
+				attrs = append(attrs, slog.Any("response_headers", redact.header(resp.Header)))
+			}
+			if config.LogBodies {
+				body, truncated, replacement, _ := peekBody(resp.Body, config.MaxBodyBytes)
+				resp.Body = replacement
+				if body != nil {
+					attrs = append(attrs, bodyAttr("response_body", redact.body(body), truncated))
+				}
+			}
+
+			level := config.Level.Level()
+			if resp.StatusCode >= http.StatusInternalServerError {
+				level = config.ErrorLevel.Level()
+			}
+			logger.LogAttrs(ctx, level, "http request", attrs...)
+
+			return resp, nil
+		}
+	}
+}
+
+func bodyAttr(key string, body []byte, truncated bool) slog.Attr {
+	if truncated {
+		return slog.String(key, string(body)+"...(truncated)")
+	}
+	return slog.String(key, string(body))
+}
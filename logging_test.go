@@ -0,0 +1,229 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer guards log output written from background refreshes
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newTestLogger() (*slog.Logger, *syncBuffer) {
+	out := &syncBuffer{}
+	return slog.New(slog.NewJSONHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug})), out
+}
+
+func decodeLogRecords(t *testing.T, out string) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Invalid log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestWithLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(TestData{Message: "hello", Status: "ok"})
+	}))
+	defer server.Close()
+
+	logger, out := newTestLogger()
+	client := NewClient(server.URL, WithLogger(logger))
+
+	resp, err := client.Get(context.Background(), "/users")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	records := decodeLogRecords(t, out.String())
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 log record, got %d", len(records))
+	}
+	record := records[0]
+
+	if record["level"] != "INFO" || record["msg"] != "http request" {
+		t.Errorf("Unexpected record %v", record)
+	}
+	if record["method"] != "GET" || record["url"] != server.URL+"/users" {
+		t.Errorf("Unexpected method or URL in %v", record)
+	}
+	if record["status"] != float64(http.StatusOK) {
+		t.Errorf("Expected status 200, got %v", record["status"])
+	}
+	if _, ok := record["duration"]; !ok {
+		t.Error("Expected duration to be logged")
+	}
+	if _, ok := record["request_headers"]; ok {
+		t.Error("Expected headers to be omitted by default")
+	}
+}
+
+func TestWithLogConfig_Redaction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t"})
+		w.Write([]byte(`{"access_token":"tok-123","user":"alice"}`))
+	}))
+	defer server.Close()
+
+	logger, out := newTestLogger()
+	client := NewClient(server.URL,
+		WithLogger(logger),
+		WithLogConfig(LogConfig{
+			LogHeaders:    true,
+			LogBodies:     true,
+			RedactHeaders: []string{"X-Api-Key"},
+			RedactFields:  []string{"password", "access_token"},
+		}),
+	)
+
+	resp, err := client.Post(context.Background(), "/login",
+		map[string]string{"user": "alice", "password": "hunter2"},
+		WithRequestHeader("Authorization", "Bearer abc"),
+		WithRequestHeader("X-API-Key", "key-456"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != `{"access_token":"tok-123","user":"alice"}` {
+		t.Errorf("Expected logging to leave the body intact, got %q", body)
+	}
+
+	logged := out.String()
+	for _, secret := range []string{"Bearer abc", "key-456", "hunter2", "tok-123", "s3cr3t"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("Expected %q to be redacted from %s", secret, logged)
+		}
+	}
+	for _, visible := range []string{"alice", "Content-Type", redactedValue} {
+		if !strings.Contains(logged, visible) {
+			t.Errorf("Expected %q in log output %s", visible, logged)
+		}
+	}
+}
+
+func TestWithLogConfig_BodyCapAndLevels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer server.Close()
+
+	logger, out := newTestLogger()
+	client := NewClient(server.URL,
+		WithLogger(logger),
+		WithLogConfig(LogConfig{
+			Level:        slog.LevelDebug,
+			ErrorLevel:   slog.LevelWarn,
+			LogBodies:    true,
+			MaxBodyBytes: 10,
+		}),
+	)
+
+	resp, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if len(body) != 100 {
+		t.Errorf("Expected the full body to remain readable, got %d bytes", len(body))
+	}
+
+	records := decodeLogRecords(t, out.String())
+	if records[0]["level"] != "WARN" {
+		t.Errorf("Expected 5xx to use the error level, got %v", records[0]["level"])
+	}
+	if records[0]["response_body"] != "xxxxxxxxxx...(truncated)" {
+		t.Errorf("Expected truncated body, got %v", records[0]["response_body"])
+	}
+}
+
+func TestWithLogger_TransportError(t *testing.T) {
+	logger, out := newTestLogger()
+	client := NewClient(deadURL(), WithLogger(logger))
+
+	if _, err := client.Get(context.Background(), "/"); err == nil {
+		t.Fatal("Expected transport error, got none")
+	}
+
+	records := decodeLogRecords(t, out.String())
+	if records[0]["level"] != "ERROR" || records[0]["msg"] != "http request failed" {
+		t.Errorf("Unexpected record %v", records[0])
+	}
+	if _, ok := records[0]["error"]; !ok {
+		t.Error("Expected error to be logged")
+	}
+}
+
+func TestCachedClient_LogsRefreshErrors(t *testing.T) {
+	var mu sync.Mutex
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(TestCacheData{Value: "test"})
+	}))
+	defer server.Close()
+
+	logger, out := newTestLogger()
+	client := NewCachedClient(server.URL, WithLogger(logger))
+	defer client.Stop()
+
+	err := client.SetupCachedEndpoint(context.Background(), CacheConfig{
+		Path:       "/data",
+		CronSpec:   "@every 50ms",
+		Expiration: time.Minute,
+	}, &TestCacheData{})
+	if err != nil {
+		t.Fatalf("Failed to setup cached endpoint: %v", err)
+	}
+
+	mu.Lock()
+	fail = true
+	mu.Unlock()
+	time.Sleep(120 * time.Millisecond)
+
+	if !strings.Contains(out.String(), `"msg":"cache refresh failed","path":"/data"`) {
+		t.Errorf("Expected refresh failure to be logged, got %s", out.String())
+	}
+}
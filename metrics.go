@@ -0,0 +1,84 @@
+package httpclient
+
+import (
+	"net/http"
+	"time"
+)
+
+// Metrics receives measurements for requests and cached endpoints.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// RequestStarted is called before an attempt is sent
+	RequestStarted(method, route string)
+	// RequestFinished is called once the response headers arrived or the attempt failed.
+	// status is 0 when err is set.
+	RequestFinished(method, route string, status int, duration time.Duration, err error)
+	// CacheHit is called when fresh cached data is served
+	CacheHit(path string)
+	// CacheMiss is called when an endpoint has no data yet or isn't cached at all
+	CacheMiss(path string)
+	// CacheStale is called when expired cached data is requested
+	CacheStale(path string)
+	// CacheRefreshed is called after every cache update attempt
+	CacheRefreshed(path string, err error)
+}
+
+// LimitMetrics may be implemented by Metrics to record the adaptive concurrency limit
+type LimitMetrics interface {
+	// ConcurrencyLimit is called with the initial limit and whenever it changes
+	ConcurrencyLimit(limit int)
+}
+
+// WithMetrics reports request and cache measurements to m
+func WithMetrics(m Metrics) Option {
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
+
+// WithRoute sets the route template used to label metrics, e.g. "/users/{id}".
+// Without it the request path is used, which may have a high cardinality.
+func WithRoute(template string) RequestOption {
+	return func(req *http.Request) {
+		if state := callStateFrom(req.Context()); state != nil {
+			state.route = template
+		}
+	}
+}
+
+// metricsMiddleware measures each attempt
+func (c *Client) metricsMiddleware() Middleware {
+	metrics := c.metrics
+
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			route := req.URL.Path
+			if state := callStateFrom(req.Context()); state != nil && state.route != "" {
+				route = state.route
+			}
+
+			metrics.RequestStarted(req.Method, route)
+			start := time.Now()
+
+			resp, err := next(req)
+
+			status := 0
+			if err == nil {
+				status = resp.StatusCode
+			}
+			metrics.RequestFinished(req.Method, route, status, time.Since(start), err)
+
+			return resp, err
+		}
+	}
+}
+
+// nopMetrics is used when no metrics are configured
+type nopMetrics struct{}
+
+func (nopMetrics) RequestStarted(string, string)                             {}
+func (nopMetrics) RequestFinished(string, string, int, time.Duration, error) {}
+func (nopMetrics) CacheHit(string)                                           {}
+func (nopMetrics) CacheMiss(string)                                          {}
+func (nopMetrics) CacheStale(string)                                         {}
+func (nopMetrics) CacheRefreshed(string, error)                              {}
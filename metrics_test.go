@@ -0,0 +1,147 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingMetrics collects every measurement as a readable event
+type recordingMetrics struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (m *recordingMetrics) record(format string, args ...interface{}) {
+	m.mu.Lock()
+	m.events = append(m.events, fmt.Sprintf(format, args...))
+	m.mu.Unlock()
+}
+
+func (m *recordingMetrics) RequestStarted(method, route string) {
+	m.record("start %s %s", method, route)
+}
+
+func (m *recordingMetrics) RequestFinished(method, route string, status int, duration time.Duration, err error) {
+	m.record("finish %s %s %d %v", method, route, status, err != nil)
+}
+
+func (m *recordingMetrics) CacheHit(path string)   { m.record("hit %s", path) }
+func (m *recordingMetrics) CacheMiss(path string)  { m.record("miss %s", path) }
+func (m *recordingMetrics) CacheStale(path string) { m.record("stale %s", path) }
+
+func (m *recordingMetrics) CacheRefreshed(path string, err error) {
+	m.record("refresh %s %v", path, err != nil)
+}
+
+func (m *recordingMetrics) snapshot() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.events...)
+}
+
+func assertEvents(t *testing.T, got, expected []string) {
+	t.Helper()
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("Expected events %v, got %v", expected, got)
+	}
+}
+
+func TestWithMetrics_Requests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	metrics := &recordingMetrics{}
+	client := NewClient(server.URL, WithMetrics(metrics))
+
+	resp, err := client.Post(context.Background(), "/users/42?expand=true", nil, WithRoute("/users/{id}"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	resp, err = client.Get(context.Background(), "/health?verbose=1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	assertEvents(t, metrics.snapshot(), []string{
+		"start POST /users/{id}",
+		"finish POST /users/{id} 201 false",
+		"start GET /health",
+		"finish GET /health 201 false",
+	})
+}
+
+func TestWithMetrics_TransportError(t *testing.T) {
+	metrics := &recordingMetrics{}
+	client := NewClient(deadURL(), WithMetrics(metrics))
+
+	if _, err := client.Get(context.Background(), "/"); err == nil {
+		t.Fatal("Expected transport error, got none")
+	}
+
+	assertEvents(t, metrics.snapshot(), []string{"start GET /", "finish GET / 0 true"})
+}
+
+func TestWithMetrics_Cache(t *testing.T) {
+	var mu sync.Mutex
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(TestCacheData{Value: "test"})
+	}))
+	defer server.Close()
+
+	metrics := &recordingMetrics{}
+	client := NewCachedClient(server.URL, WithMetrics(metrics))
+	defer client.Stop()
+
+	err := client.SetupCachedEndpoint(context.Background(), CacheConfig{
+		Path:             "/prices",
+		CronSpec:         "@every 1h",
+		Expiration:       50 * time.Millisecond,
+		SkipInitialFetch: true,
+	}, &TestCacheData{})
+	if err != nil {
+		t.Fatalf("Failed to setup cached endpoint: %v", err)
+	}
+
+	client.GetCached("/unknown")
+	client.GetCachedOrFetch(context.Background(), "/prices")
+	client.GetCached("/prices")
+
+	time.Sleep(75 * time.Millisecond)
+	mu.Lock()
+	fail = true
+	mu.Unlock()
+	client.GetCachedOrFetch(context.Background(), "/prices")
+
+	var cacheEvents []string
+	for _, event := range metrics.snapshot() {
+		if event[:5] != "start" && event[:6] != "finish" {
+			cacheEvents = append(cacheEvents, event)
+		}
+	}
+	assertEvents(t, cacheEvents, []string{
+		"miss /unknown",
+		"miss /prices",
+		"refresh /prices false",
+		"hit /prices",
+		"stale /prices",
+		"refresh /prices true",
+	})
+}
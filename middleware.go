@@ -0,0 +1,46 @@
+package httpclient
+
+import "net/http"
+
+// Handler performs a single request
+type Handler func(*http.Request) (*http.Response, error)
+
+// Middleware wraps a Handler to observe or alter request execution,
+// e.g. for logging, metrics, authentication or retries
+type Middleware func(next Handler) Handler
+
+// WithMiddleware appends middleware around request execution.
+// Middleware registered first runs outermost and sees the request first and the response last.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, mw...)
+	}
+}
+
+// buildHandler wraps execute with the built-in middleware enabled by options,
+// then with the registered middleware so user code sees the outermost view
+func (c *Client) buildHandler() Handler {
+	handler := Handler(c.execute)
+	chain := append(append([]Middleware{}, c.middleware...), c.internal...)
+	for i := len(chain) - 1; i >= 0; i-- {
+		handler = chain[i](handler)
+	}
+	return handler
+}
+
+// execute is the innermost handler that sends the request over the wire
+func (c *Client) execute(req *http.Request) (*http.Response, error) {
+	if err := c.runBeforeHooks(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if rec, ok := req.Context().Value(timingsKey{}).(*timingsRecorder); ok {
+		rec.observe(resp, err)
+	}
+	if err == nil {
+		c.limitBody(req, resp)
+	}
+
+	return c.runAfterHooks(resp, err)
+}
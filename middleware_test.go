@@ -0,0 +1,115 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWithMiddleware_Order(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("X-Trail")))
+	}))
+	defer server.Close()
+
+	var trail []string
+	tag := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(req *http.Request) (*http.Response, error) {
+				trail = append(trail, name+" before")
+				req.Header.Add("X-Trail", name)
+				resp, err := next(req)
+				trail = append(trail, name+" after")
+				return resp, err
+			}
+		}
+	}
+
+	client := NewClient(server.URL, WithMiddleware(tag("outer"), tag("middle")), WithMiddleware(tag("inner")))
+	resp, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	expected := "outer before,middle before,inner before,inner after,middle after,outer after"
+	if got := strings.Join(trail, ","); got != expected {
+		t.Errorf("Expected order %q, got %q", expected, got)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "outer" {
+		t.Errorf("Expected server to see the first header value, got %q", body)
+	}
+}
+
+func TestWithMiddleware_ShortCircuit(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+
+	errBlocked := errors.New("blocked")
+	client := NewClient(server.URL, WithMiddleware(func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/admin" {
+				return nil, errBlocked
+			}
+			return next(req)
+		}
+	}))
+
+	if _, err := client.Get(context.Background(), "/admin"); !errors.Is(err, errBlocked) {
+		t.Errorf("Expected blocked error, got %v", err)
+	}
+	if hits != 0 {
+		t.Errorf("Expected request not to reach the server, got %d hits", hits)
+	}
+}
+
+func TestWithMiddleware_Retry(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if atomic.AddInt32(&hits, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	retry := func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			for {
+				resp, err := next(req)
+				if err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+					return resp, err
+				}
+				resp.Body.Close()
+				req.Body, _ = req.GetBody()
+			}
+		}
+	}
+
+	client := NewClient(server.URL, WithMiddleware(retry))
+	resp, err := client.Post(context.Background(), "/", map[string]string{"k": "v"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != `{"k":"v"}` {
+		t.Errorf("Expected replayed body, got %q", body)
+	}
+	if hits != 3 {
+		t.Errorf("Expected 3 attempts, got %d", hits)
+	}
+}
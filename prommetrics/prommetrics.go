@@ -0,0 +1,158 @@
+// Package prommetrics exposes httpclient request and cache metrics as a Prometheus collector.
+//
+// Usage:
+//
+//	metrics := prommetrics.New(prommetrics.Options{})
+//	prometheus.MustRegister(metrics)
+//
+//	client := httpclient.NewCachedClient("https://api.example.com", httpclient.WithMetrics(metrics))
+package prommetrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samhoque/httpclient"
+)
+
+// Options defines how metrics are named and bucketed
+type Options struct {
+	Namespace   string            // Metric name prefix (default "httpclient")
+	Buckets     []float64         // Latency histogram buckets in seconds (default prometheus.DefBuckets)
+	ConstLabels prometheus.Labels // Labels added to every metric, e.g. the upstream name
+}
+
+// Collector implements httpclient.Metrics and prometheus.Collector
+type Collector struct {
+	requests        *prometheus.CounterVec
+	duration        *prometheus.HistogramVec
+	inFlight        *prometheus.GaugeVec
+	errors          *prometheus.CounterVec
+	cacheHits       *prometheus.CounterVec
+	cacheMisses     *prometheus.CounterVec
+	cacheStale      *prometheus.CounterVec
+	refreshFailures *prometheus.CounterVec
+	lastRefresh     *prometheus.GaugeVec
+	limit           prometheus.Gauge
+}
+
+var _ httpclient.Metrics = (*Collector)(nil)
+var _ httpclient.LimitMetrics = (*Collector)(nil)
+var _ prometheus.Collector = (*Collector)(nil)
+
+// New creates a collector, register it with a prometheus.Registerer before use
+func New(opts Options) *Collector {
+	if opts.Namespace == "" {
+		opts.Namespace = "httpclient"
+	}
+	if opts.Buckets == nil {
+		opts.Buckets = prometheus.DefBuckets
+	}
+
+	counter := func(name, help string, labels ...string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.Namespace, Name: name, Help: help, ConstLabels: opts.ConstLabels,
+		}, labels)
+	}
+	gauge := func(name, help string, labels ...string) *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: opts.Namespace, Name: name, Help: help, ConstLabels: opts.ConstLabels,
+		}, labels)
+	}
+
+	return &Collector{
+		requests: counter("requests_total", "Completed requests by method, route and status.", "method", "route", "status"),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   opts.Namespace,
+			Name:        "request_duration_seconds",
+			Help:        "Time until response headers were received.",
+			Buckets:     opts.Buckets,
+			ConstLabels: opts.ConstLabels,
+		}, []string{"method", "route", "status"}),
+		inFlight:        gauge("requests_in_flight", "Requests currently awaiting a response.", "method", "route"),
+		errors:          counter("request_errors_total", "Requests that failed without a response.", "method", "route"),
+		cacheHits:       counter("cache_hits_total", "Fresh cached data served.", "path"),
+		cacheMisses:     counter("cache_misses_total", "Cache lookups without data.", "path"),
+		cacheStale:      counter("cache_stale_total", "Expired cached data requested.", "path"),
+		refreshFailures: counter("cache_refresh_failures_total", "Failed cache updates.", "path"),
+		lastRefresh:     gauge("cache_last_success_timestamp_seconds", "Unix time of the last successful cache update.", "path"),
+		limit: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   opts.Namespace,
+			Name:        "concurrency_limit",
+			Help:        "Current adaptive concurrency limit.",
+			ConstLabels: opts.ConstLabels,
+		}),
+	}
+}
+
+// RequestStarted implements httpclient.Metrics
+func (c *Collector) RequestStarted(method, route string) {
+	c.inFlight.WithLabelValues(method, route).Inc()
+}
+
+// RequestFinished implements httpclient.Metrics
+func (c *Collector) RequestFinished(method, route string, status int, duration time.Duration, err error) {
+	c.inFlight.WithLabelValues(method, route).Dec()
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(status)
+	} else {
+		c.errors.WithLabelValues(method, route).Inc()
+	}
+
+	c.requests.WithLabelValues(method, route, code).Inc()
+	c.duration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// CacheHit implements httpclient.Metrics
+func (c *Collector) CacheHit(path string) {
+	c.cacheHits.WithLabelValues(path).Inc()
+}
+
+// CacheMiss implements httpclient.Metrics
+func (c *Collector) CacheMiss(path string) {
+	c.cacheMisses.WithLabelValues(path).Inc()
+}
+
+// CacheStale implements httpclient.Metrics
+func (c *Collector) CacheStale(path string) {
+	c.cacheStale.WithLabelValues(path).Inc()
+}
+
+// CacheRefreshed implements httpclient.Metrics
+func (c *Collector) CacheRefreshed(path string, err error) {
+	if err != nil {
+		c.refreshFailures.WithLabelValues(path).Inc()
+		return
+	}
+	c.lastRefresh.WithLabelValues(path).SetToCurrentTime()
+}
+
+// ConcurrencyLimit implements httpclient.LimitMetrics
+func (c *Collector) ConcurrencyLimit(limit int) {
+	c.limit.Set(float64(limit))
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.collectors() {
+		m.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range c.collectors() {
+		m.Collect(ch)
+	}
+}
+
+func (c *Collector) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.requests, c.duration, c.inFlight, c.errors,
+		c.cacheHits, c.cacheMisses, c.cacheStale, c.refreshFailures, c.lastRefresh,
+		c.limit,
+	}
+}
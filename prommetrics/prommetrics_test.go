@@ -0,0 +1,115 @@
+package prommetrics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/samhoque/httpclient"
+)
+
+func TestCollector_Requests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	metrics := New(Options{})
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(metrics)
+
+	client := httpclient.NewClient(server.URL, httpclient.WithMetrics(metrics))
+	for _, path := range []string{"/users/1", "/users/2", "/missing"} {
+		resp, err := client.Get(context.Background(), path, httpclient.WithRoute("/users/{id}"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	expected := `
+# HELP httpclient_requests_total Completed requests by method, route and status.
+# TYPE httpclient_requests_total counter
+httpclient_requests_total{method="GET",route="/users/{id}",status="200"} 2
+httpclient_requests_total{method="GET",route="/users/{id}",status="404"} 1
+# HELP httpclient_requests_in_flight Requests currently awaiting a response.
+# TYPE httpclient_requests_in_flight gauge
+httpclient_requests_in_flight{method="GET",route="/users/{id}"} 0
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"httpclient_requests_total", "httpclient_requests_in_flight"); err != nil {
+		t.Error(err)
+	}
+
+	if n := testutil.CollectAndCount(metrics, "httpclient_request_duration_seconds"); n != 2 {
+		t.Errorf("Expected 2 latency series, got %d", n)
+	}
+}
+
+func TestCollector_Errors(t *testing.T) {
+	metrics := New(Options{Namespace: "upstream"})
+	metrics.RequestStarted("GET", "/")
+	metrics.RequestFinished("GET", "/", 0, time.Millisecond, context.DeadlineExceeded)
+
+	if v := testutil.ToFloat64(metrics.errors.WithLabelValues("GET", "/")); v != 1 {
+		t.Errorf("Expected 1 error, got %v", v)
+	}
+	if v := testutil.ToFloat64(metrics.requests.WithLabelValues("GET", "/", "error")); v != 1 {
+		t.Errorf("Expected request with error status, got %v", v)
+	}
+}
+
+func TestCollector_Cache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"value": "test"})
+	}))
+	defer server.Close()
+
+	metrics := New(Options{})
+	client := httpclient.NewCachedClient(server.URL, httpclient.WithMetrics(metrics))
+	defer client.Stop()
+
+	var data map[string]string
+	err := client.SetupCachedEndpoint(context.Background(), httpclient.CacheConfig{
+		Path:       "/prices",
+		CronSpec:   "@every 1h",
+		Expiration: time.Minute,
+	}, &data)
+	if err != nil {
+		t.Fatalf("Failed to setup cached endpoint: %v", err)
+	}
+
+	client.GetCached("/prices")
+	client.GetCached("/prices")
+	client.GetCached("/unknown")
+
+	if v := testutil.ToFloat64(metrics.cacheHits.WithLabelValues("/prices")); v != 2 {
+		t.Errorf("Expected 2 hits, got %v", v)
+	}
+	if v := testutil.ToFloat64(metrics.cacheMisses.WithLabelValues("/unknown")); v != 1 {
+		t.Errorf("Expected 1 miss, got %v", v)
+	}
+	if v := testutil.ToFloat64(metrics.lastRefresh.WithLabelValues("/prices")); v < float64(time.Now().Add(-time.Minute).Unix()) {
+		t.Errorf("Expected a recent last success timestamp, got %v", v)
+	}
+}
+
+func TestCollector_ConcurrencyLimit(t *testing.T) {
+	metrics := New(Options{})
+	httpclient.NewClient("http://example.com",
+		httpclient.WithMetrics(metrics),
+		httpclient.WithAdaptiveLimit(httpclient.AdaptiveLimitConfig{InitialLimit: 12}),
+	)
+
+	if v := testutil.ToFloat64(metrics.limit); v != 12 {
+		t.Errorf("Expected the initial limit 12, got %v", v)
+	}
+}
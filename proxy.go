@@ -0,0 +1,164 @@
+package httpclient
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ProxyFunc selects the proxy for a request, returning nil for a direct connection
+type ProxyFunc func(*http.Request) (*url.URL, error)
+
+// WithProxy routes all requests through the given proxy.
+// Supported schemes are http, https, socks5 and socks5h; credentials in the URL
+// are used for proxy authentication.
+// It has no effect when a custom transport is set with WithTransport.
+func WithProxy(proxyURL string) Option {
+	u, err := url.Parse(proxyURL)
+	return func(c *Client) {
+		c.proxyFunc = func(*http.Request) (*url.URL, error) {
+			if err != nil {
+				return nil, fmt.Errorf("invalid proxy URL: %w", err)
+			}
+			return u, nil
+		}
+	}
+}
+
+// WithProxyFunc selects the proxy per request, e.g. based on the target host.
+// It has no effect when a custom transport is set with WithTransport.
+func WithProxyFunc(fn ProxyFunc) Option {
+	return func(c *Client) {
+		c.proxyFunc = fn
+	}
+}
+
+// WithProxyAuth sets credentials for proxies that don't carry their own in the URL.
+// It has no effect when a custom transport is set with WithTransport.
+func WithProxyAuth(username, password string) Option {
+	return func(c *Client) {
+		c.proxyAuth = url.UserPassword(username, password)
+	}
+}
+
+// WithNoProxy excludes hosts from proxying using NO_PROXY syntax:
+// "*" matches everything, "example.com" matches the domain and its subdomains,
+// ".example.com" matches subdomains only, and IPs or CIDR ranges match literal addresses.
+// Any entry may carry a ":port" suffix to only match that port.
+// It has no effect when a custom transport is set with WithTransport.
+func WithNoProxy(hosts ...string) Option {
+	return func(c *Client) {
+		for _, host := range hosts {
+			if rule, ok := parseNoProxyRule(host); ok {
+				c.noProxy = append(c.noProxy, rule)
+			}
+		}
+	}
+}
+
+// proxy is installed on the transport when any proxy option is used
+func (c *Client) proxy(req *http.Request) (*url.URL, error) {
+	for _, rule := range c.noProxy {
+		if rule.match(req.URL) {
+			return nil, nil
+		}
+	}
+
+	fn := c.proxyFunc
+	if fn == nil {
+		fn = http.ProxyFromEnvironment
+	}
+
+	u, err := fn(req)
+	if err != nil || u == nil {
+		return u, err
+	}
+
+	if u.User == nil && c.proxyAuth != nil {
+		withAuth := *u
+		withAuth.User = c.proxyAuth
+		u = &withAuth
+	}
+	return u, nil
+}
+
+// noProxyRule is a single parsed NO_PROXY entry
+type noProxyRule struct {
+	all       bool
+	domain    string // always starts with "."
+	matchHost bool   // domain also matches the bare host
+	ip        net.IP
+	network   *net.IPNet
+	port      string
+}
+
+func parseNoProxyRule(entry string) (noProxyRule, bool) {
+	entry = strings.ToLower(strings.TrimSpace(entry))
+	if entry == "" {
+		return noProxyRule{}, false
+	}
+	if entry == "*" {
+		return noProxyRule{all: true}, true
+	}
+
+	if _, network, err := net.ParseCIDR(entry); err == nil {
+		return noProxyRule{network: network}, true
+	}
+
+	host, port := entry, ""
+	if h, p, err := net.SplitHostPort(entry); err == nil {
+		host, port = h, p
+	}
+	host = strings.Trim(host, "[]")
+
+	if ip := net.ParseIP(host); ip != nil {
+		return noProxyRule{ip: ip, port: port}, true
+	}
+
+	rule := noProxyRule{port: port}
+	switch {
+	case strings.HasPrefix(host, "*."):
+		rule.domain = host[1:]
+	case strings.HasPrefix(host, "."):
+		rule.domain = host
+	default:
+		rule.domain = "." + host
+		rule.matchHost = true
+	}
+	return rule, true
+}
+
+func (r noProxyRule) match(u *url.URL) bool {
+	if r.all {
+		return true
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if r.port != "" && r.port != portOf(u) {
+		return false
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if r.network != nil {
+			return r.network.Contains(ip)
+		}
+		return r.ip != nil && r.ip.Equal(ip)
+	}
+	if r.domain == "" {
+		return false
+	}
+	return strings.HasSuffix(host, r.domain) || (r.matchHost && host == r.domain[1:])
+}
+
+// portOf returns the explicit port of the URL or the default port for its scheme
+func portOf(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	if u.Scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
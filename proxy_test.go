@@ -0,0 +1,285 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+)
+
+// newForwardProxy returns a plain HTTP proxy stand-in that answers on behalf of any target
+func newForwardProxy(t *testing.T, hits *int32, gotAuth *atomic.Value) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		if gotAuth != nil {
+			gotAuth.Store(r.Header.Get("Proxy-Authorization"))
+		}
+		json.NewEncoder(w).Encode(TestData{Message: "proxied " + r.URL.Host, Status: "ok"})
+	}))
+}
+
+func TestWithProxy(t *testing.T) {
+	var hits int32
+	var gotAuth atomic.Value
+	proxy := newForwardProxy(t, &hits, &gotAuth)
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	proxyURL.User = url.UserPassword("user", "secret")
+
+	client := NewClient("http://backend.test", WithProxy(proxyURL.String()))
+	resp, err := client.Get(context.Background(), "/users")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var data TestData
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if data.Message != "proxied backend.test" {
+		t.Errorf("Expected proxied response, got %q", data.Message)
+	}
+
+	expected := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:secret"))
+	if auth, _ := gotAuth.Load().(string); auth != expected {
+		t.Errorf("Expected Proxy-Authorization %q, got %q", expected, auth)
+	}
+}
+
+func TestWithProxyAuth(t *testing.T) {
+	var hits int32
+	var gotAuth atomic.Value
+	proxy := newForwardProxy(t, &hits, &gotAuth)
+	defer proxy.Close()
+
+	client := NewClient("http://backend.test",
+		WithProxy(proxy.URL),
+		WithProxyAuth("alice", "pw"),
+	)
+	resp, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	expected := "Basic " + base64.StdEncoding.EncodeToString([]byte("alice:pw"))
+	if auth, _ := gotAuth.Load().(string); auth != expected {
+		t.Errorf("Expected Proxy-Authorization %q, got %q", expected, auth)
+	}
+}
+
+func TestWithProxy_InvalidURL(t *testing.T) {
+	client := NewClient("http://backend.test", WithProxy("://bad"))
+	if _, err := client.Get(context.Background(), "/"); err == nil {
+		t.Error("Expected error for invalid proxy URL, got none")
+	}
+}
+
+func TestWithProxyFunc(t *testing.T) {
+	var hits int32
+	proxy := newForwardProxy(t, &hits, nil)
+	defer proxy.Close()
+
+	direct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(TestData{Message: "direct", Status: "ok"})
+	}))
+	defer direct.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	selector := func(req *http.Request) (*url.URL, error) {
+		if req.URL.Hostname() == "backend.test" {
+			return proxyURL, nil
+		}
+		return nil, nil
+	}
+
+	proxied := NewClient("http://backend.test", WithProxyFunc(selector))
+	resp, err := proxied.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	unproxied := NewClient(direct.URL, WithProxyFunc(selector))
+	resp, err = unproxied.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if hits != 1 {
+		t.Errorf("Expected exactly 1 proxied request, got %d", hits)
+	}
+}
+
+func TestWithNoProxy(t *testing.T) {
+	var hits int32
+	proxy := newForwardProxy(t, &hits, nil)
+	defer proxy.Close()
+
+	direct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer direct.Close()
+
+	client := NewClient(direct.URL, WithProxy(proxy.URL), WithNoProxy("127.0.0.0/8"))
+	resp, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if hits != 0 {
+		t.Errorf("Expected request to bypass the proxy, got %d proxied requests", hits)
+	}
+}
+
+func TestNoProxyRule_Match(t *testing.T) {
+	tests := []struct {
+		entry    string
+		target   string
+		expected bool
+	}{
+		{"*", "http://anything.test", true},
+		{"example.com", "http://example.com", true},
+		{"example.com", "http://api.example.com", true},
+		{"example.com", "http://notexample.com", false},
+		{".example.com", "http://example.com", false},
+		{".example.com", "http://api.example.com", true},
+		{"*.example.com", "http://api.example.com", true},
+		{"example.com:8080", "http://example.com:8080", true},
+		{"example.com:8080", "http://example.com", false},
+		{"example.com:443", "https://example.com", true},
+		{"10.0.0.0/8", "http://10.1.2.3", true},
+		{"10.0.0.0/8", "http://192.168.0.1", false},
+		{"192.168.0.1", "http://192.168.0.1:9000", true},
+		{"[::1]:80", "http://[::1]", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry+" "+tt.target, func(t *testing.T) {
+			rule, ok := parseNoProxyRule(tt.entry)
+			if !ok {
+				t.Fatalf("Failed to parse rule %q", tt.entry)
+			}
+			u, _ := url.Parse(tt.target)
+			if got := rule.match(u); got != tt.expected {
+				t.Errorf("Expected match %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// serveSOCKS5 is a minimal SOCKS5 stand-in supporting CONNECT with username/password auth
+func serveSOCKS5(t *testing.T, username, password string, target string) (addr string, hits *int32) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	hits = new(int32)
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				buf := make([]byte, 512)
+
+				// Greeting: version, method count, methods
+				if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+					return
+				}
+				if _, err := io.ReadFull(conn, buf[:buf[1]]); err != nil {
+					return
+				}
+				conn.Write([]byte{5, 2})
+
+				// Username/password sub-negotiation
+				if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+					return
+				}
+				user := make([]byte, buf[1])
+				io.ReadFull(conn, user)
+				io.ReadFull(conn, buf[:1])
+				pass := make([]byte, buf[0])
+				io.ReadFull(conn, pass)
+				if string(user) != username || string(pass) != password {
+					conn.Write([]byte{1, 1})
+					return
+				}
+				conn.Write([]byte{1, 0})
+
+				// CONNECT request; the destination is ignored in favour of the target
+				if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+					return
+				}
+				switch buf[3] {
+				case 1:
+					io.ReadFull(conn, buf[:4+2])
+				case 3:
+					io.ReadFull(conn, buf[:1])
+					io.ReadFull(conn, buf[:int(buf[0])+2])
+				case 4:
+					io.ReadFull(conn, buf[:16+2])
+				}
+
+				upstream, err := net.Dial("tcp", target)
+				if err != nil {
+					conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+					return
+				}
+				defer upstream.Close()
+				atomic.AddInt32(hits, 1)
+
+				conn.Write([]byte{5, 0, 0, 1, 127, 0, 0, 1, 0, 0})
+
+				go io.Copy(upstream, conn)
+				io.Copy(conn, upstream)
+			}(conn)
+		}
+	}()
+
+	return ln.Addr().String(), hits
+}
+
+func TestWithProxy_SOCKS5(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(TestData{Message: "via socks " + r.Host, Status: "ok"})
+	}))
+	defer backend.Close()
+
+	backendURL, _ := url.Parse(backend.URL)
+	addr, hits := serveSOCKS5(t, "user", "secret", backendURL.Host)
+
+	client := NewClient("http://backend.test", WithProxy("socks5://user:secret@"+addr))
+	resp, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var data TestData
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if data.Message != "via socks backend.test" {
+		t.Errorf("Unexpected response %q", data.Message)
+	}
+	if atomic.LoadInt32(hits) != 1 {
+		t.Errorf("Expected 1 SOCKS5 connection, got %d", atomic.LoadInt32(hits))
+	}
+}
@@ -0,0 +1,56 @@
+package httpclient
+
+import (
+	"net/http"
+	"regexp"
+)
+
+// redactedValue replaces secrets in logs and dumps
+const redactedValue = "[REDACTED]"
+
+// sensitiveHeaders are always redacted
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// redactor masks secret header values and JSON fields
+type redactor struct {
+	headers map[string]bool
+	fields  []*regexp.Regexp
+}
+
+func newRedactor(headers, fields []string) *redactor {
+	r := &redactor{headers: make(map[string]bool)}
+	for _, h := range append(sensitiveHeaders, headers...) {
+		r.headers[http.CanonicalHeaderKey(h)] = true
+	}
+	for _, f := range fields {
+		// Matches string, number, boolean and null values so truncated bodies are covered too
+		r.fields = append(r.fields, regexp.MustCompile(`(?i)("`+regexp.QuoteMeta(f)+`"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`))
+	}
+	return r
+}
+
+// sensitive reports whether the header value must be masked
+func (r *redactor) sensitive(name string) bool {
+	return r.headers[http.CanonicalHeaderKey(name)]
+}
+
+// header returns a copy of h with sensitive values masked
+func (r *redactor) header(h http.Header) http.Header {
+	masked := make(http.Header, len(h))
+	for k, v := range h {
+		if r.sensitive(k) {
+			masked[k] = []string{redactedValue}
+			continue
+		}
+		masked[k] = v
+	}
+	return masked
+}
+
+// body masks the values of sensitive JSON fields
+func (r *redactor) body(b []byte) []byte {
+	for _, field := range r.fields {
+		b = field.ReplaceAll(b, []byte(`${1}"`+redactedValue+`"`))
+	}
+	return b
+}
@@ -0,0 +1,131 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// WithSingleflight makes concurrent identical GET requests share one upstream call.
+// Requests are identical when their URL and the values of the given headers match.
+// Each caller receives its own copy of the response, whose body is read fully first.
+// The shared call is cancelled once every caller waiting for it has given up.
+// Callers only share a call when WithRoute, WithPriority and WithRequestMaxResponseBytes
+// match, and requests using WithTimings are never shared.
+func WithSingleflight(headers ...string) Option {
+	return func(c *Client) {
+		c.singleflight = &singleflight{headers: headers, calls: make(map[string]*flight)}
+	}
+}
+
+type singleflight struct {
+	headers []string
+
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// flight is one shared upstream call
+type flight struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+// shareable reports whether req may join or start a shared call.
+// Timings trace a single request, so a joiner could never see its own.
+func (s *singleflight) shareable(req *http.Request) bool {
+	_, traced := req.Context().Value(timingsKey{}).(*timingsRecorder)
+	return !traced
+}
+
+// key identifies requests that may share a call
+func (s *singleflight) key(req *http.Request) string {
+	var b strings.Builder
+	b.WriteString(req.URL.String())
+	for _, name := range s.headers {
+		b.WriteString("\n" + http.CanonicalHeaderKey(name) + ": ")
+		b.WriteString(strings.Join(req.Header.Values(name), ","))
+	}
+	// The call runs with the options of the caller starting it, so these must match too
+	if state := callStateFrom(req.Context()); state != nil {
+		fmt.Fprintf(&b, "\nroute=%s priority=%d limit=%d", state.route, state.priority, state.maxResponseBytes)
+	}
+	return b.String()
+}
+
+// do joins the call in flight for key or starts one with fn
+func (s *singleflight) do(ctx context.Context, key string, fn func(context.Context) (*http.Response, error)) (*http.Response, error) {
+	s.mu.Lock()
+	f, ok := s.calls[key]
+	if !ok {
+		// The shared call outlives the caller that started it, keeping its context values
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		s.calls[key] = f
+		go s.run(callCtx, key, f, fn)
+	}
+	f.waiters++
+	s.mu.Unlock()
+
+	select {
+	case <-f.done:
+		if f.err != nil {
+			return nil, f.err
+		}
+		return f.response(), nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			f.cancel()
+			s.forget(key, f)
+		}
+		s.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (s *singleflight) run(ctx context.Context, key string, f *flight, fn func(context.Context) (*http.Response, error)) {
+	defer f.cancel()
+
+	resp, err := fn(ctx)
+	if err == nil {
+		f.body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			err = fmt.Errorf("failed to read response body: %w", err)
+		}
+	}
+	f.resp, f.err = resp, err
+
+	s.mu.Lock()
+	s.forget(key, f)
+	s.mu.Unlock()
+	close(f.done)
+}
+
+// forget removes f so later requests start a new call, must be called with s.mu held
+func (s *singleflight) forget(key string, f *flight) {
+	if s.calls[key] == f {
+		delete(s.calls, key)
+	}
+}
+
+// response returns an independent copy of the shared response
+func (f *flight) response() *http.Response {
+	resp := *f.resp
+	resp.Header = f.resp.Header.Clone()
+	resp.Trailer = f.resp.Trailer.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(f.body))
+	resp.ContentLength = int64(len(f.body))
+	return &resp
+}
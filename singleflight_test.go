@@ -0,0 +1,183 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithSingleflight(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+		w.Header().Set("X-Tenant", r.Header.Get("X-Tenant"))
+		w.Write([]byte("shared"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithSingleflight("X-Tenant"))
+
+	var wg sync.WaitGroup
+	bodies := make([]string, 10)
+	responses := make([]*http.Response, 10)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tenant := "a"
+			if i == 9 {
+				tenant = "b"
+			}
+			resp, err := client.Get(context.Background(), "/items", WithRequestHeader("X-Tenant", tenant))
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			bodies[i], responses[i] = string(body), resp
+		}(i)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if hits != 2 {
+		t.Errorf("Expected one upstream call per tenant, got %d", hits)
+	}
+	for i, body := range bodies {
+		if body != "shared" {
+			t.Errorf("Expected caller %d to get the full body, got %q", i, body)
+		}
+	}
+	if responses[9].Header.Get("X-Tenant") != "b" {
+		t.Errorf("Expected tenant b to get its own response, got %q", responses[9].Header.Get("X-Tenant"))
+	}
+	responses[0].Header.Set("X-Tenant", "changed")
+	if responses[1].Header.Get("X-Tenant") != "a" {
+		t.Error("Expected each caller to get independent headers")
+	}
+}
+
+func TestWithSingleflight_SequentialAndOtherMethods(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithSingleflight())
+
+	for i := 0; i < 2; i++ {
+		getBody(t, client, "/")
+		resp, err := client.Post(context.Background(), "/", nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if hits != 4 {
+		t.Errorf("Expected completed calls not to be shared, got %d hits", hits)
+	}
+}
+
+func TestWithSingleflight_CancelledWaiters(t *testing.T) {
+	cancelled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			close(cancelled)
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithSingleflight())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	other, cancelOther := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	for _, ctx := range []context.Context{ctx, other} {
+		go func(ctx context.Context) {
+			_, err := client.Get(ctx, "/")
+			errs <- err
+		}(ctx)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancelled caller to give up, got %v", err)
+	}
+	select {
+	case <-cancelled:
+		t.Fatal("Expected the shared call to continue while a caller waits")
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	cancelOther()
+	<-errs
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("Expected the shared call to be cancelled once every caller gave up")
+	}
+}
+
+func TestWithSingleflight_PerRequestOptions(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithSingleflight())
+
+	var timed int32
+	callers := [][]RequestOption{
+		nil,
+		nil,
+		{WithRoute("/items/{id}")},
+		{WithPriority(PriorityHigh)},
+		{WithRequestMaxResponseBytes(10)},
+		{WithTimings(func(Timings) { atomic.AddInt32(&timed, 1) })},
+	}
+
+	var wg sync.WaitGroup
+	responses := make([]*http.Response, len(callers))
+	for i, opts := range callers {
+		wg.Add(1)
+		go func(i int, opts []RequestOption) {
+			defer wg.Done()
+			resp, err := client.Get(context.Background(), "/items", opts...)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			resp.Body.Close()
+			responses[i] = resp
+		}(i, opts)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if hits != 5 {
+		t.Errorf("Expected only the two callers without options to share a call, got %d upstream calls", hits)
+	}
+	if _, ok := TimingsFromResponse(responses[5]); !ok || atomic.LoadInt32(&timed) != 1 {
+		t.Errorf("Expected the caller using WithTimings to get its timings, got callbacks %d", timed)
+	}
+}
@@ -0,0 +1,168 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings breaks down where the time of a request was spent.
+// Phases that didn't happen, e.g. DNS on a reused connection, are zero.
+type Timings struct {
+	DNS        time.Duration // Host name lookup
+	Connect    time.Duration // TCP connection establishment
+	TLS        time.Duration // TLS handshake
+	FirstByte  time.Duration // From the request being written until the first response byte
+	Total      time.Duration // From the request being built until the body was closed, or until the headers while it's still open, including time queued for a bulkhead or adaptive limit slot
+	ConnReused bool          // Whether an idle keep-alive connection was reused
+}
+
+// timingsKey carries the recorder of a traced request
+type timingsKey struct{}
+
+// WithTimings records a Timings breakdown for the request, available through
+// TimingsFromResponse. The optional callback runs once the response body is closed
+// or the request failed.
+func WithTimings(callback ...func(Timings)) RequestOption {
+	return func(req *http.Request) {
+		rec := &timingsRecorder{start: time.Now(), callbacks: callback}
+		ctx := context.WithValue(req.Context(), timingsKey{}, rec)
+		*req = *req.WithContext(httptrace.WithClientTrace(ctx, rec.trace()))
+	}
+}
+
+// TimingsFromResponse returns the timings of a request sent with WithTimings
+func TimingsFromResponse(resp *http.Response) (Timings, bool) {
+	if resp == nil || resp.Request == nil {
+		return Timings{}, false
+	}
+	rec, ok := resp.Request.Context().Value(timingsKey{}).(*timingsRecorder)
+	if !ok {
+		return Timings{}, false
+	}
+	return rec.snapshot(), true
+}
+
+type timingsRecorder struct {
+	mu        sync.Mutex
+	start     time.Time
+	dnsStart  time.Time
+	connStart time.Time
+	tlsStart  time.Time
+	wrote     time.Time
+	timings   Timings
+	done      bool
+	callbacks []func(Timings)
+}
+
+func (r *timingsRecorder) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			r.mu.Lock()
+			r.dnsStart = time.Now()
+			r.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			r.mu.Lock()
+			r.timings.DNS = time.Since(r.dnsStart)
+			r.mu.Unlock()
+		},
+		ConnectStart: func(string, string) {
+			r.mu.Lock()
+			// Parallel dials to several addresses count from the first one
+			if r.connStart.IsZero() {
+				r.connStart = time.Now()
+			}
+			r.mu.Unlock()
+		},
+		ConnectDone: func(_, _ string, err error) {
+			r.mu.Lock()
+			if err == nil {
+				r.timings.Connect = time.Since(r.connStart)
+			}
+			r.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			r.mu.Lock()
+			r.tlsStart = time.Now()
+			r.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			r.mu.Lock()
+			r.timings.TLS = time.Since(r.tlsStart)
+			r.mu.Unlock()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			r.mu.Lock()
+			r.timings.ConnReused = info.Reused
+			r.mu.Unlock()
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			r.mu.Lock()
+			r.wrote = time.Now()
+			r.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			r.mu.Lock()
+			// Servers may answer before the request body was fully written
+			from := r.wrote
+			if from.IsZero() {
+				from = r.start
+			}
+			r.timings.FirstByte = time.Since(from)
+			r.mu.Unlock()
+		},
+	}
+}
+
+func (r *timingsRecorder) snapshot() Timings {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.timings
+}
+
+// observe records the total once the headers arrived, and again when the body is closed
+func (r *timingsRecorder) observe(resp *http.Response, err error) {
+	r.mu.Lock()
+	r.timings.Total = time.Since(r.start)
+	r.mu.Unlock()
+
+	if err != nil {
+		r.finish()
+		return
+	}
+	resp.Body = &timedBody{ReadCloser: resp.Body, recorder: r}
+}
+
+// finish stops the clock and runs the callbacks once
+func (r *timingsRecorder) finish() {
+	r.mu.Lock()
+	if r.done {
+		r.mu.Unlock()
+		return
+	}
+	r.done = true
+	r.timings.Total = time.Since(r.start)
+	timings := r.timings
+	r.mu.Unlock()
+
+	for _, callback := range r.callbacks {
+		callback(timings)
+	}
+}
+
+// timedBody finishes the timings when the body is closed
+type timedBody struct {
+	io.ReadCloser
+	recorder *timingsRecorder
+}
+
+func (b *timedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.recorder.finish()
+	return err
+}
@@ -0,0 +1,130 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithTimings(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithTransportConfig(TransportConfig{
+		TLSClientConfig: server.Client().Transport.(*http.Transport).TLSClientConfig,
+	}))
+
+	var reported []Timings
+	callback := func(timings Timings) {
+		reported = append(reported, timings)
+	}
+
+	resp, err := client.Get(context.Background(), "/", WithTimings(callback))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	timings, ok := TimingsFromResponse(resp)
+	if !ok {
+		t.Fatal("Expected timings on the response")
+	}
+	if timings.Connect <= 0 || timings.TLS <= 0 {
+		t.Errorf("Expected connect and TLS timings on a new connection, got %+v", timings)
+	}
+	if timings.FirstByte < 50*time.Millisecond {
+		t.Errorf("Expected time to first byte to include server time, got %v", timings.FirstByte)
+	}
+	if timings.ConnReused {
+		t.Error("Expected a new connection")
+	}
+	if len(reported) != 0 {
+		t.Error("Expected callback to wait for the body to be closed")
+	}
+
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	resp.Body.Close()
+
+	if len(reported) != 1 {
+		t.Fatalf("Expected callback once, got %d calls", len(reported))
+	}
+	if reported[0].Total < reported[0].FirstByte {
+		t.Errorf("Expected total to cover the whole request, got %+v", reported[0])
+	}
+
+	resp, err = client.Get(context.Background(), "/", WithTimings())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	timings, _ = TimingsFromResponse(resp)
+	if !timings.ConnReused {
+		t.Error("Expected the keep-alive connection to be reused")
+	}
+	if timings.Connect != 0 || timings.TLS != 0 {
+		t.Errorf("Expected no connect or TLS time on a reused connection, got %+v", timings)
+	}
+}
+
+func TestWithTimings_FirstByteExcludesWaiting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	// Stands in for time spent before the request is written, like a bulkhead queue
+	client.OnBeforeRequest(func(req *http.Request) error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	})
+
+	resp, err := client.Get(context.Background(), "/", WithTimings())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	timings, _ := TimingsFromResponse(resp)
+	if timings.FirstByte < 20*time.Millisecond || timings.FirstByte >= 100*time.Millisecond {
+		t.Errorf("Expected FirstByte to cover only the server time, got %v", timings.FirstByte)
+	}
+	if timings.Total < 120*time.Millisecond {
+		t.Errorf("Expected Total to include the wait, got %v", timings.Total)
+	}
+}
+
+func TestWithTimings_Error(t *testing.T) {
+	client := NewClient(deadURL())
+
+	var calls int
+	_, err := client.Get(context.Background(), "/", WithTimings(func(Timings) { calls++ }))
+	if err == nil {
+		t.Fatal("Expected transport error, got none")
+	}
+	if calls != 1 {
+		t.Errorf("Expected callback on failure, got %d calls", calls)
+	}
+}
+
+func TestTimingsFromResponse_NotEnabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	resp, err := NewClient(server.URL).Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if _, ok := TimingsFromResponse(resp); ok {
+		t.Error("Expected no timings without WithTimings")
+	}
+}
@@ -0,0 +1,104 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const tracerName = "github.com/samhoque/httpclient"
+
+// cachePathKey links CachedClient refresh spans to the endpoint being refreshed
+const cachePathKey = attribute.Key("httpclient.cache.path")
+
+// TracingConfig defines the OpenTelemetry integration
+type TracingConfig struct {
+	TracerProvider trace.TracerProvider          // Defaults to the global provider
+	Propagator     propagation.TextMapPropagator // Defaults to W3C trace context and baggage
+}
+
+// WithTracing creates a client span for every request attempt, following the HTTP
+// semantic conventions, and injects the trace context into the request headers.
+// CachedClient background refreshes get a span of their own.
+func WithTracing(config TracingConfig) Option {
+	return func(c *Client) {
+		if config.TracerProvider == nil {
+			config.TracerProvider = otel.GetTracerProvider()
+		}
+		if config.Propagator == nil {
+			config.Propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+		}
+		c.tracer = config.TracerProvider.Tracer(tracerName)
+		c.propagator = config.Propagator
+	}
+}
+
+// tracingMiddleware wraps each attempt in a client span
+func (c *Client) tracingMiddleware() Middleware {
+	tracer, propagator := c.tracer, c.propagator
+
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			attrs := []attribute.KeyValue{
+				semconv.HTTPRequestMethodKey.String(req.Method),
+				semconv.URLFull(req.URL.Redacted()),
+				semconv.ServerAddress(req.URL.Hostname()),
+			}
+			if port, err := strconv.Atoi(portOf(req.URL)); err == nil {
+				attrs = append(attrs, semconv.ServerPort(port))
+			}
+			if req.ContentLength > 0 {
+				attrs = append(attrs, semconv.HTTPRequestBodySize(int(req.ContentLength)))
+			}
+
+			ctx, span := tracer.Start(req.Context(), req.Method,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...),
+			)
+			defer span.End()
+
+			if attempt := nextAttempt(req.Context()); attempt > 0 {
+				span.SetAttributes(semconv.HTTPRequestResendCount(attempt))
+				span.AddEvent("retry", trace.WithAttributes(attribute.Int("attempt", attempt)))
+			}
+
+			req = req.WithContext(ctx)
+			propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			resp, err := next(req)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				span.SetAttributes(semconv.ErrorTypeKey.String(fmt.Sprintf("%T", err)))
+				return resp, err
+			}
+
+			span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+			if resp.StatusCode >= http.StatusBadRequest {
+				span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+				span.SetAttributes(semconv.ErrorTypeKey.String(strconv.Itoa(resp.StatusCode)))
+			}
+			return resp, nil
+		}
+	}
+}
+
+// startCacheSpan starts a span for a CachedClient refresh, a no-op without tracing
+func (c *Client) startCacheSpan(ctx context.Context, path string) (context.Context, trace.Span) {
+	if c.tracer == nil {
+		return ctx, noop.Span{}
+	}
+	return c.tracer.Start(ctx, "cache refresh "+path,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(cachePathKey.String(path)),
+	)
+}
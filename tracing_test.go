@@ -0,0 +1,190 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestTracing() (TracingConfig, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return TracingConfig{TracerProvider: provider}, recorder
+}
+
+func spanAttr(span sdktrace.ReadOnlySpan, key string) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestWithTracing(t *testing.T) {
+	var traceparent, bag string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		bag = r.Header.Get("baggage")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config, recorder := newTestTracing()
+	client := NewClient(server.URL, WithTracing(config))
+
+	member, _ := baggage.NewMember("tenant", "acme")
+	b, _ := baggage.New(member)
+	ctx := baggage.ContextWithBaggage(context.Background(), b)
+
+	resp, err := client.Get(ctx, "/users")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+
+	if span.Name() != "GET" || span.SpanKind() != trace.SpanKindClient {
+		t.Errorf("Unexpected span %q of kind %v", span.Name(), span.SpanKind())
+	}
+	if v, _ := spanAttr(span, "http.request.method"); v.AsString() != "GET" {
+		t.Errorf("Expected method attribute, got %v", v)
+	}
+	if v, _ := spanAttr(span, "url.full"); v.AsString() != server.URL+"/users" {
+		t.Errorf("Expected url.full attribute, got %v", v)
+	}
+	if v, _ := spanAttr(span, "http.response.status_code"); v.AsInt64() != http.StatusOK {
+		t.Errorf("Expected status attribute, got %v", v)
+	}
+
+	expected := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"
+	if traceparent != expected {
+		t.Errorf("Expected traceparent %q, got %q", expected, traceparent)
+	}
+	if bag != "tenant=acme" {
+		t.Errorf("Expected baggage header, got %q", bag)
+	}
+}
+
+func TestWithTracing_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	config, recorder := newTestTracing()
+	client := NewClient(server.URL, WithTracing(config))
+
+	resp, err := client.Get(context.Background(), "/missing")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	span := recorder.Ended()[0]
+	if span.Status().Code != codes.Error {
+		t.Errorf("Expected error status for 404, got %v", span.Status())
+	}
+	if v, _ := spanAttr(span, "error.type"); v.AsString() != "404" {
+		t.Errorf("Expected error.type 404, got %v", v)
+	}
+}
+
+func TestWithTracing_Retries(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	retryOnce := func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			if err == nil && resp.StatusCode == http.StatusServiceUnavailable {
+				resp.Body.Close()
+				return next(req)
+			}
+			return resp, err
+		}
+	}
+
+	config, recorder := newTestTracing()
+	client := NewClient(server.URL, WithMiddleware(retryOnce), WithTracing(config))
+
+	resp, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected a span per attempt, got %d", len(spans))
+	}
+	if _, ok := spanAttr(spans[0], "http.request.resend_count"); ok {
+		t.Error("Expected no resend count on the first attempt")
+	}
+	if v, _ := spanAttr(spans[1], "http.request.resend_count"); v.AsInt64() != 1 {
+		t.Errorf("Expected resend count 1, got %v", v)
+	}
+	if events := spans[1].Events(); len(events) != 1 || events[0].Name != "retry" {
+		t.Errorf("Expected a retry event, got %v", events)
+	}
+}
+
+func TestCachedClient_RefreshSpans(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(TestCacheData{Value: "test"})
+	}))
+	defer server.Close()
+
+	config, recorder := newTestTracing()
+	client := NewCachedClient(server.URL, WithTracing(config))
+	defer client.Stop()
+
+	err := client.SetupCachedEndpoint(context.Background(), CacheConfig{
+		Path:       "/prices",
+		CronSpec:   "@every 1h",
+		Expiration: time.Minute,
+	}, &TestCacheData{})
+	if err != nil {
+		t.Fatalf("Failed to setup cached endpoint: %v", err)
+	}
+
+	var refresh, request sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		switch span.Name() {
+		case "cache refresh /prices":
+			refresh = span
+		case "GET":
+			request = span
+		}
+	}
+	if refresh == nil || request == nil {
+		t.Fatalf("Expected refresh and request spans, got %d spans", len(recorder.Ended()))
+	}
+	if v, _ := spanAttr(refresh, "httpclient.cache.path"); v.AsString() != "/prices" {
+		t.Errorf("Expected cache path attribute, got %v", v)
+	}
+	if request.Parent().SpanID() != refresh.SpanContext().SpanID() {
+		t.Error("Expected the request span to be a child of the refresh span")
+	}
+}
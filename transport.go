@@ -0,0 +1,89 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportConfig tunes the connection pool and timeouts of the underlying transport.
+// Zero values leave the corresponding default untouched.
+type TransportConfig struct {
+	MaxIdleConns          int           // Maximum idle connections across all hosts
+	MaxIdleConnsPerHost   int           // Maximum idle connections kept per host
+	MaxConnsPerHost       int           // Maximum total connections per host, 0 means no limit
+	IdleConnTimeout       time.Duration // How long an idle connection stays in the pool
+	DialTimeout           time.Duration // Maximum time to establish a TCP connection
+	KeepAlive             time.Duration // TCP keep-alive period, negative disables keep-alives
+	TLSHandshakeTimeout   time.Duration // Maximum time to wait for a TLS handshake
+	ResponseHeaderTimeout time.Duration // Maximum time to wait for response headers after writing the request
+	ExpectContinueTimeout time.Duration // Maximum time to wait for a 100-continue response
+	DisableKeepAlives     bool          // Use a new connection for every request
+	TLSClientConfig       *tls.Config   // Custom TLS configuration
+}
+
+// newTransport returns a transport with the same defaults as http.DefaultTransport,
+// dialing through the given dialer so the dial settings can be tuned later
+func newTransport(dialer *net.Dialer) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// WithTransportConfig tunes the connection pool and timeouts of the default transport.
+// It has no effect when a custom transport is set with WithTransport.
+func WithTransportConfig(config TransportConfig) Option {
+	return func(c *Client) {
+		if config.MaxIdleConns > 0 {
+			c.transport.MaxIdleConns = config.MaxIdleConns
+		}
+		if config.MaxIdleConnsPerHost > 0 {
+			c.transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+		}
+		if config.MaxConnsPerHost > 0 {
+			c.transport.MaxConnsPerHost = config.MaxConnsPerHost
+		}
+		if config.IdleConnTimeout > 0 {
+			c.transport.IdleConnTimeout = config.IdleConnTimeout
+		}
+		if config.DialTimeout > 0 {
+			c.dialer.Timeout = config.DialTimeout
+		}
+		if config.KeepAlive != 0 {
+			c.dialer.KeepAlive = config.KeepAlive
+		}
+		if config.TLSHandshakeTimeout > 0 {
+			c.transport.TLSHandshakeTimeout = config.TLSHandshakeTimeout
+		}
+		if config.ResponseHeaderTimeout > 0 {
+			c.transport.ResponseHeaderTimeout = config.ResponseHeaderTimeout
+		}
+		if config.ExpectContinueTimeout > 0 {
+			c.transport.ExpectContinueTimeout = config.ExpectContinueTimeout
+		}
+		if config.DisableKeepAlives {
+			c.transport.DisableKeepAlives = true
+		}
+		if config.TLSClientConfig != nil {
+			c.transport.TLSClientConfig = config.TLSClientConfig
+		}
+	}
+}
+
+// WithTransport replaces the underlying transport with a custom http.RoundTripper.
+// Options configuring the default transport are then ignored: WithTransportConfig, WithDialer,
+// WithUnixSocket, WithDNSCache, WithProxy, WithProxyFunc, WithProxyAuth and WithNoProxy.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.client.Transport = rt
+	}
+}
+
+// CloseIdleConnections closes any idle keep-alive connections held by the transport.
+// It reaches the base transport directly since wrappers like WithDebug don't forward it.
+func (c *Client) CloseIdleConnections() {
+	if closer, ok := c.baseTransport.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
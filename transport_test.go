@@ -0,0 +1,147 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWithTransportConfig(t *testing.T) {
+	client := NewClient("https://api.example.com", WithTransportConfig(TransportConfig{
+		MaxIdleConns:          50,
+		MaxIdleConnsPerHost:   10,
+		MaxConnsPerHost:       20,
+		IdleConnTimeout:       time.Minute,
+		DialTimeout:           2 * time.Second,
+		KeepAlive:             -1,
+		TLSHandshakeTimeout:   3 * time.Second,
+		ResponseHeaderTimeout: 4 * time.Second,
+		DisableKeepAlives:     true,
+	}))
+
+	tr := client.transport
+	if tr.MaxIdleConns != 50 {
+		t.Errorf("Expected MaxIdleConns 50, got %d", tr.MaxIdleConns)
+	}
+	if tr.MaxIdleConnsPerHost != 10 {
+		t.Errorf("Expected MaxIdleConnsPerHost 10, got %d", tr.MaxIdleConnsPerHost)
+	}
+	if tr.MaxConnsPerHost != 20 {
+		t.Errorf("Expected MaxConnsPerHost 20, got %d", tr.MaxConnsPerHost)
+	}
+	if tr.IdleConnTimeout != time.Minute {
+		t.Errorf("Expected IdleConnTimeout 1m, got %v", tr.IdleConnTimeout)
+	}
+	if tr.TLSHandshakeTimeout != 3*time.Second {
+		t.Errorf("Expected TLSHandshakeTimeout 3s, got %v", tr.TLSHandshakeTimeout)
+	}
+	if tr.ResponseHeaderTimeout != 4*time.Second {
+		t.Errorf("Expected ResponseHeaderTimeout 4s, got %v", tr.ResponseHeaderTimeout)
+	}
+	if !tr.DisableKeepAlives {
+		t.Error("Expected keep-alives to be disabled")
+	}
+	if client.dialer.Timeout != 2*time.Second {
+		t.Errorf("Expected dial timeout 2s, got %v", client.dialer.Timeout)
+	}
+	if client.dialer.KeepAlive != -1 {
+		t.Errorf("Expected negative keep-alive, got %v", client.dialer.KeepAlive)
+	}
+}
+
+func TestWithTransportConfig_ResponseHeaderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithTransportConfig(TransportConfig{
+		ResponseHeaderTimeout: 50 * time.Millisecond,
+	}))
+
+	_, err := client.Get(context.Background(), "/slow")
+	if err == nil {
+		t.Fatal("Expected response header timeout, got none")
+	}
+	if !strings.Contains(err.Error(), "timeout awaiting response headers") {
+		t.Errorf("Expected response header timeout error, got: %v", err)
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	var called bool
+	client := NewClient("https://api.example.com", WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		called = true
+		return &http.Response{
+			StatusCode: http.StatusTeapot,
+			Body:       io.NopCloser(strings.NewReader("")),
+			Header:     make(http.Header),
+			Request:    req,
+		}, nil
+	})))
+
+	resp, err := client.Get(context.Background(), "/brew")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if !called {
+		t.Error("Expected custom transport to be used")
+	}
+	if resp.StatusCode != http.StatusTeapot {
+		t.Errorf("Expected status %d, got %d", http.StatusTeapot, resp.StatusCode)
+	}
+
+	// Must not panic for transports without idle connections
+	client.CloseIdleConnections()
+}
+
+func TestClient_CloseIdleConnections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	resp, err := client.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	client.CloseIdleConnections()
+}
+
+// idleClosingTransport counts calls to CloseIdleConnections
+type idleClosingTransport struct {
+	roundTripFunc
+	closed int32
+}
+
+func (t *idleClosingTransport) CloseIdleConnections() {
+	atomic.AddInt32(&t.closed, 1)
+}
+
+func TestClient_CloseIdleConnections_Wrapped(t *testing.T) {
+	rt := &idleClosingTransport{}
+	client := NewClient("https://api.example.com", WithTransport(rt), WithDebug(io.Discard), WithCurlHook(func(string) {}))
+
+	client.CloseIdleConnections()
+
+	if closed := atomic.LoadInt32(&rt.closed); closed != 1 {
+		t.Errorf("Expected CloseIdleConnections to reach the base transport once, got %d", closed)
+	}
+}